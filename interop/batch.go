@@ -0,0 +1,290 @@
+package interop
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Script statuses in a BatchReport
+const (
+	StatusPass  = "pass"
+	StatusFail  = "fail"
+	StatusError = "error"
+	StatusSkip  = "skip"
+)
+
+// Files next to a script: the expected output and an optional stdin
+const (
+	expectedOutputExt = ".out"
+	inputExt          = ".in"
+)
+
+type batchConfig struct {
+	parallelism int
+	timeout     time.Duration
+	sandbox     *Sandbox
+}
+
+type BatchOpt func(*batchConfig)
+
+// WithParallelism bounds how many scripts run at once, defaults to the number of CPUs
+func WithParallelism(n int) BatchOpt {
+	return func(c *batchConfig) {
+		if n > 0 {
+			c.parallelism = n
+		}
+	}
+}
+
+// WithScriptTimeout sets the Interop.Timeout of every script
+func WithScriptTimeout(timeout time.Duration) BatchOpt {
+	return func(c *batchConfig) {
+		c.timeout = timeout
+	}
+}
+
+// WithBatchSandbox runs every script in sandbox
+func WithBatchSandbox(sandbox *Sandbox) BatchOpt {
+	return func(c *batchConfig) {
+		c.sandbox = sandbox
+	}
+}
+
+// BatchReport aggregates the results of RunBatch
+type BatchReport struct {
+	Dir      string         `json:"dir"`
+	Passed   int            `json:"passed"`
+	Failed   int            `json:"failed"`
+	Errors   int            `json:"errors"`
+	Skipped  int            `json:"skipped"`
+	Duration time.Duration  `json:"duration"`
+	Results  []ScriptResult `json:"results"`
+}
+
+// ScriptResult is the outcome of a single script of a batch
+type ScriptResult struct {
+	// Name is the script file name relative to the batch directory
+	Name     string        `json:"name"`
+	Language string        `json:"language"`
+	Status   string        `json:"status"`
+	Duration time.Duration `json:"duration"`
+	Want     string        `json:"want,omitempty"`
+	Got      string        `json:"got,omitempty"`
+	Message  string        `json:"message,omitempty"`
+}
+
+// RunBatch runs every script in dir that has a registered extension and compares its
+// stdout with the expected output in "<script>.out" next to it, trailing newlines
+// ignored. "<script>.in" is fed to stdin when present. Scripts without expected
+// output, or whose interpreter is not installed, are skipped.
+func RunBatch(ctx context.Context, dir string, opts ...BatchOpt) (*BatchReport, error) {
+	cfg := &batchConfig{parallelism: runtime.NumCPU()}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var scripts []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if _, ok := lookupFactory(Interop{FilePath: entry.Name()}); ok {
+			scripts = append(scripts, entry.Name())
+		}
+	}
+	sort.Strings(scripts)
+
+	report := &BatchReport{Dir: dir, Results: make([]ScriptResult, len(scripts))}
+	start := time.Now()
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, cfg.parallelism)
+	for n, script := range scripts {
+		wg.Add(1)
+		go func(n int, script string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			report.Results[n] = runBatchScript(ctx, cfg, dir, script)
+		}(n, script)
+	}
+	wg.Wait()
+
+	report.Duration = time.Since(start)
+	for _, res := range report.Results {
+		switch res.Status {
+		case StatusPass:
+			report.Passed++
+		case StatusFail:
+			report.Failed++
+		case StatusError:
+			report.Errors++
+		case StatusSkip:
+			report.Skipped++
+		}
+	}
+
+	return report, nil
+}
+
+func runBatchScript(ctx context.Context, cfg *batchConfig, dir string, script string) ScriptResult {
+	path := filepath.Join(dir, script)
+	res := ScriptResult{Name: script, Language: languageOf(Interop{FilePath: script})}
+
+	want, err := os.ReadFile(path + expectedOutputExt)
+	if err != nil {
+		res.Status = StatusSkip
+		res.Message = "no expected output"
+		return res
+	}
+	res.Want = strings.TrimRight(string(want), "\r\n")
+
+	var stdin io.Reader
+	if input, err := os.Open(path + inputExt); err == nil {
+		defer input.Close()
+		stdin = input
+	}
+
+	var stdout strings.Builder
+	result, err := NewInteropRunner(Interop{
+		FilePath: path,
+		Timeout:  cfg.timeout,
+		Sandbox:  cfg.sandbox,
+	}).RunStream(ctx, stdin, &stdout, nil)
+	if result != nil {
+		res.Duration = result.Duration
+	}
+	res.Got = strings.TrimRight(stdout.String(), "\r\n")
+
+	switch {
+	case errors.Is(err, ErrInterpreterNotFound):
+		res.Status = StatusSkip
+		res.Message = err.Error()
+	case err != nil:
+		res.Status = StatusError
+		res.Message = err.Error()
+		if result != nil && result.Stderr != "" {
+			res.Message += "\nstderr: " + result.Stderr
+		}
+	case res.Got != res.Want:
+		res.Status = StatusFail
+		res.Message = "output mismatch"
+	default:
+		res.Status = StatusPass
+	}
+
+	return res
+}
+
+// WriteText writes a human readable summary
+func (r *BatchReport) WriteText(w io.Writer) error {
+	var b strings.Builder
+	for _, res := range r.Results {
+		fmt.Fprintf(&b, "%-5s %s (%s) %s\n", strings.ToUpper(res.Status), res.Name, res.Language, res.Duration.Round(time.Millisecond))
+		switch res.Status {
+		case StatusFail:
+			fmt.Fprintf(&b, "      want: %q\n      got:  %q\n", res.Want, res.Got)
+		case StatusError, StatusSkip:
+			for _, line := range strings.Split(strings.TrimRight(res.Message, "\n"), "\n") {
+				fmt.Fprintf(&b, "      %s\n", line)
+			}
+		}
+	}
+	fmt.Fprintf(&b, "%d passed, %d failed, %d errors, %d skipped in %s\n", r.Passed, r.Failed, r.Errors, r.Skipped, r.Duration.Round(time.Millisecond))
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteJSON writes the report as indented JSON, durations in nanoseconds
+func (r *BatchReport) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+type junitTestSuite struct {
+	XMLName  xml.Name        `xml:"testsuite"`
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the report as a JUnit XML test suite
+func (r *BatchReport) WriteJUnit(w io.Writer) error {
+	suite := junitTestSuite{
+		Name:     r.Dir,
+		Tests:    len(r.Results),
+		Failures: r.Failed,
+		Errors:   r.Errors,
+		Skipped:  r.Skipped,
+		Time:     junitSeconds(r.Duration),
+	}
+
+	for _, res := range r.Results {
+		tc := junitTestCase{
+			ClassName: "interop." + res.Language,
+			Name:      res.Name,
+			Time:      junitSeconds(res.Duration),
+		}
+		switch res.Status {
+		case StatusFail:
+			tc.Failure = &junitMessage{Message: res.Message, Body: fmt.Sprintf("want: %q\ngot:  %q", res.Want, res.Got)}
+		case StatusError:
+			tc.Error = &junitMessage{Message: strings.SplitN(res.Message, "\n", 2)[0], Body: res.Message}
+		case StatusSkip:
+			tc.Skipped = &junitMessage{Message: res.Message}
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suite); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
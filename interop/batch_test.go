@@ -0,0 +1,98 @@
+package interop
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunBatch(t *testing.T) {
+	report, err := RunBatch(context.Background(), getAbs(TEST_DATA_PATH), WithParallelism(4), WithScriptTimeout(30*time.Second))
+	if err != nil {
+		t.Fatalf("RunBatch() error = %v", err)
+	}
+
+	statuses := map[string]string{}
+	for _, res := range report.Results {
+		statuses[res.Name] = res.Status
+		if res.Status == StatusFail || res.Status == StatusError {
+			t.Errorf("%s: %s %s", res.Name, res.Status, res.Message)
+		}
+	}
+
+	want := map[string]string{
+		"example.js":    StatusPass,
+		"example.py":    StatusPass,
+		"echo.py":       StatusPass,
+		"sleep.py":      StatusSkip,
+		"rpc_worker.py": StatusSkip,
+	}
+	for name, status := range want {
+		if statuses[name] != status {
+			t.Errorf("RunBatch() %s = %q, want %q", name, statuses[name], status)
+		}
+	}
+}
+
+func TestBatchReport(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"pass.py":      `print("ok")`,
+		"pass.py.out":  "ok\n",
+		"fail.py":      `print("nope")`,
+		"fail.py.out":  "ok",
+		"error.py":     `raise SystemExit(3)`,
+		"error.py.out": "",
+		"notes.txt":    "not a script",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report, err := RunBatch(context.Background(), dir, WithParallelism(2))
+	if err != nil {
+		t.Fatalf("RunBatch() error = %v", err)
+	}
+	if report.Passed != 1 || report.Failed != 1 || report.Errors != 1 || len(report.Results) != 3 {
+		t.Fatalf("RunBatch() = %+v", report)
+	}
+
+	var text bytes.Buffer
+	if err := report.WriteText(&text); err != nil {
+		t.Fatalf("WriteText() error = %v", err)
+	}
+	for _, want := range []string{"PASS  pass.py (python)", "FAIL  fail.py (python)", "ERROR error.py (python)", "1 passed, 1 failed, 1 errors, 0 skipped"} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("WriteText() = %s, want it to contain %q", text.String(), want)
+		}
+	}
+
+	var jsonOut bytes.Buffer
+	if err := report.WriteJSON(&jsonOut); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+	var decoded BatchReport
+	if err := json.Unmarshal(jsonOut.Bytes(), &decoded); err != nil || decoded.Passed != 1 {
+		t.Errorf("WriteJSON() = %s, %v", jsonOut.String(), err)
+	}
+
+	var junit bytes.Buffer
+	if err := report.WriteJUnit(&junit); err != nil {
+		t.Fatalf("WriteJUnit() error = %v", err)
+	}
+	var suite junitTestSuite
+	if err := xml.Unmarshal(junit.Bytes(), &suite); err != nil {
+		t.Fatalf("WriteJUnit() = %s, %v", junit.String(), err)
+	}
+	if suite.Tests != 3 || suite.Failures != 1 || suite.Errors != 1 {
+		t.Errorf("WriteJUnit() suite = %+v", suite)
+	}
+}
@@ -0,0 +1,103 @@
+package interop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ExitError is returned when a script ran to completion but exited with a non-zero code
+type ExitError struct {
+	ExitCode int
+	Stderr   string
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("failed to run script: exit status %d", e.ExitCode)
+}
+
+// TimeoutError is returned when a script was killed because its deadline expired
+type TimeoutError struct {
+	Timeout time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	if e.Timeout <= 0 {
+		return "script killed: deadline exceeded"
+	}
+	return fmt.Sprintf("script killed: timed out after %s", e.Timeout)
+}
+
+// Unwrap makes errors.Is(err, context.DeadlineExceeded) hold for timeouts
+func (e *TimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// ErrCanceled is returned when a script was killed because its context was canceled
+var ErrCanceled = errors.New("script killed: context canceled")
+
+// CompileError is returned when a script had to be compiled before running and compilation failed
+type CompileError struct {
+	// Diagnostics holds the compiler output
+	Diagnostics string
+}
+
+func (e *CompileError) Error() string {
+	return fmt.Sprintf("failed to compile script:\n%s", e.Diagnostics)
+}
+
+// ErrUnsupportedLanguage is returned when no runner is registered for the requested language
+var ErrUnsupportedLanguage = errors.New("unsupported language")
+
+var (
+	// ErrInterpreterNotFound is wrapped by InterpreterError when no interpreter binary was found
+	ErrInterpreterNotFound = errors.New("interpreter not found")
+	// ErrInterpreterVersion is wrapped by InterpreterError when the interpreter is too old or too new
+	ErrInterpreterVersion = errors.New("interpreter version does not satisfy constraint")
+)
+
+// InterpreterError is returned before a script runs when no usable interpreter was found
+type InterpreterError struct {
+	Name       string
+	Path       string
+	Version    string
+	Constraint string
+	// Searched lists the locations looked at, in order
+	Searched []string
+	Err      error
+}
+
+func (e *InterpreterError) Error() string {
+	switch {
+	case errors.Is(e.Err, ErrInterpreterNotFound):
+		return fmt.Sprintf("%v: %s (searched %s)", e.Err, e.Name, strings.Join(e.Searched, ", "))
+	case errors.Is(e.Err, ErrInterpreterVersion):
+		return fmt.Sprintf("%v: %s is %s, want %s", e.Err, e.Path, e.Version, e.Constraint)
+	default:
+		return fmt.Sprintf("interpreter %s: %v", e.Name, e.Err)
+	}
+}
+
+func (e *InterpreterError) Unwrap() error {
+	return e.Err
+}
+
+// ErrLimitExceeded is wrapped by LimitError
+var ErrLimitExceeded = errors.New("sandbox limit exceeded")
+
+// LimitError is returned when a sandboxed script was stopped for exceeding a resource limit
+type LimitError struct {
+	// Resource is one of LimitCPU, LimitMemory or LimitOutput
+	Resource string
+	Limit    string
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%v: %s limit of %s", ErrLimitExceeded, e.Resource, e.Limit)
+}
+
+func (e *LimitError) Unwrap() error {
+	return ErrLimitExceeded
+}
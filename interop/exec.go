@@ -0,0 +1,140 @@
+package interop
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"time"
+)
+
+// waitDelay bounds how long Wait blocks on output pipes after the process was killed
+const waitDelay = time.Second
+
+// runCommand starts name with args and wires the given streams to the process.
+// A non-nil Result is returned whenever the process was started.
+func runCommand(ctx context.Context, i Interop, name string, args []string, stdin io.Reader, stdout, stderr io.Writer) (*Result, error) {
+	if i.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, i.Timeout)
+		defer cancel()
+	}
+
+	// kill stops the process without canceling the caller context, e.g. on a sandbox limit
+	killCtx, kill := context.WithCancel(ctx)
+	defer kill()
+
+	cmd := exec.CommandContext(killCtx, name, args...)
+	setProcessGroup(cmd)
+	cmd.WaitDelay = waitDelay
+
+	var stderrBuf bytes.Buffer
+	cmd.Stdout = stdout
+	cmd.Stderr = &stderrBuf
+	if stderr != nil {
+		cmd.Stderr = io.MultiWriter(&stderrBuf, stderr)
+	}
+
+	var sandbox *sandboxRun
+	if i.Sandbox != nil {
+		var err error
+		if sandbox, err = i.Sandbox.prepare(cmd, kill); err != nil {
+			return nil, err
+		}
+		defer sandbox.cleanup()
+	}
+
+	// Copy stdin ourselves instead of setting cmd.Stdin: Wait would otherwise block
+	// until stdin is drained, even after the process exited, when the reader never
+	// returns EOF (e.g. an io.Pipe kept open by a long-lived caller). The copy is
+	// stopped once Wait returns.
+	var stdinPipe io.WriteCloser
+	if stdin != nil {
+		var err error
+		if stdinPipe, err = cmd.StdinPipe(); err != nil {
+			return nil, fmt.Errorf("failed to open stdin: %v", err)
+		}
+	}
+
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start script: %v", err)
+	}
+
+	if stdinPipe != nil {
+		stopStdin := copyStdin(stdinPipe, stdin)
+		defer stopStdin()
+	}
+
+	err := cmd.Wait()
+	res := &Result{
+		ExitCode: cmd.ProcessState.ExitCode(),
+		Duration: time.Since(start),
+		Stderr:   stderrBuf.String(),
+	}
+
+	if sandbox != nil {
+		if limitErr := sandbox.exceeded(cmd.ProcessState, res.Stderr); limitErr != nil {
+			return res, limitErr
+		}
+	}
+
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		if errors.Is(ctxErr, context.DeadlineExceeded) {
+			return res, &TimeoutError{Timeout: i.Timeout}
+		}
+		return res, ErrCanceled
+	}
+
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return res, &ExitError{ExitCode: res.ExitCode, Stderr: res.Stderr}
+		}
+		return res, err
+	}
+
+	return res, nil
+}
+
+// copyStdin copies src into the process stdin until src is drained or the
+// returned stop is called once the process exited. A Read blocked in src is
+// interrupted through its read deadline where src has one, e.g. an *os.File
+// pipe or a net.Conn, other readers end the copy on their next Read.
+func copyStdin(dst io.WriteCloser, src io.Reader) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		io.Copy(dst, src)
+		dst.Close()
+	}()
+
+	return func() {
+		// fails the pending write, if any, the copy cannot outlive the process
+		dst.Close()
+
+		d, ok := src.(interface{ SetReadDeadline(time.Time) error })
+		if !ok || d.SetReadDeadline(time.Now()) != nil {
+			return
+		}
+		<-done
+		d.SetReadDeadline(time.Time{})
+	}
+}
+
+// runBuffered runs a command through runStream and returns its stdout
+// the way Run has always reported it.
+func runBuffered(ctx context.Context, runStream func(context.Context, io.Reader, io.Writer, io.Writer) (*Result, error)) (string, error) {
+	var stdout bytes.Buffer
+	res, err := runStream(ctx, nil, &stdout, nil)
+	if err != nil {
+		if res == nil || res.Stderr == "" {
+			return "", err
+		}
+		return "", fmt.Errorf("%w\nstderr: %s", err, res.Stderr)
+	}
+
+	return stdout.String(), nil
+}
@@ -1,22 +1,42 @@
 package interop
 
+import (
+	"io"
+	"time"
+)
+
 // Interop represents the interoperability interface between Go and other languages
 type Interop struct {
+	// Language is a registered language name or alias, detected from FilePath when empty
 	Language string
 	FilePath string
-	Args     []string
+	// Source is inline script code run instead of the file at FilePath, FilePath then
+	// only names the temp file the source may be written to. It is read once, on the
+	// first run, and every run of the same runner executes the same source.
+	Source io.Reader
+	Args   []string
+	// Interpreter overrides the interpreter binary, a path or a name looked up in PATH.
+	// For java it names the java binary, javac is taken from the same directory.
+	Interpreter string
+	// Version constrains the interpreter version, e.g. "3.8" (a minimum) or ">=18, <21"
+	Version string
+	// Timeout kills the script once it has been running for this long, zero means no timeout
+	Timeout time.Duration
+	// Sandbox restricts resources and environment of the script, nil runs it unrestricted
+	Sandbox *Sandbox
 }
 
-// NewInteropRunner creates a new InteropRunner instance
+// NewInteropRunner creates a new InteropRunner instance for the registered language.
+// When Language is empty the language is detected from the FilePath extension,
+// an unsupported language yields an UnknownRunner that fails with ErrUnsupportedLanguage.
 func NewInteropRunner(interop Interop) InteropRunner {
-	switch interop.Language {
-	case "javascript", "js":
-		return &JavascriptRunner{I: interop}
-	case "java":
-		return &JavaRunner{I: interop}
-	case "python":
-		return &PythonRunner{I: interop}
-	default:
-		return &UnknownRunner{}
+	if interop.Source != nil {
+		interop.Source = newSharedSource(interop.Source)
+	}
+
+	factory, ok := lookupFactory(interop)
+	if !ok {
+		return &UnknownRunner{I: interop}
 	}
+	return factory(interop)
 }
@@ -1,6 +1,8 @@
 package interop
 
 import (
+	"errors"
+	"maps"
 	"reflect"
 	"testing"
 )
@@ -40,6 +42,59 @@ func TestNewInteropRunner(t *testing.T) {
 				FilePath: getAbs(TEST_DATA_PATH, "example.py"),
 			}},
 		},
+		{
+			name: "Must PythonRunner by alias",
+			args: args{
+				interop: Interop{
+					Language: "py",
+					FilePath: getAbs(TEST_DATA_PATH, "example.py"),
+				},
+			},
+			want: &PythonRunner{Interop{
+				Language: "py",
+				FilePath: getAbs(TEST_DATA_PATH, "example.py"),
+			}},
+		},
+		{
+			name: "Must JavaRunner by extension",
+			args: args{
+				interop: Interop{
+					FilePath: getAbs(TEST_DATA_PATH, "example.java"),
+				},
+			},
+			want: &JavaRunner{Interop{
+				FilePath: getAbs(TEST_DATA_PATH, "example.java"),
+			}},
+		},
+		{
+			name: "Must ScriptRunner for ruby",
+			args: args{
+				interop: Interop{
+					Language: "ruby",
+					FilePath: getAbs(TEST_DATA_PATH, "example.rb"),
+				},
+			},
+			want: &ScriptRunner{
+				I: Interop{
+					Language: "ruby",
+					FilePath: getAbs(TEST_DATA_PATH, "example.rb"),
+				},
+				Interpreter: "ruby",
+			},
+		},
+		{
+			name: "Must UnknownRunner",
+			args: args{
+				interop: Interop{
+					Language: "cobol",
+					FilePath: "example.cob",
+				},
+			},
+			want: &UnknownRunner{Interop{
+				Language: "cobol",
+				FilePath: "example.cob",
+			}},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -49,3 +104,46 @@ func TestNewInteropRunner(t *testing.T) {
 		})
 	}
 }
+
+func TestUnsupportedLanguage(t *testing.T) {
+	_, err := NewInteropRunner(Interop{Language: "cobol"}).Run()
+	if !errors.Is(err, ErrUnsupportedLanguage) {
+		t.Errorf("UnknownRunner.Run() error = %v, want ErrUnsupportedLanguage", err)
+	}
+}
+
+// restoreRegistry puts the registry back the way it was once t is done,
+// so tests that register languages do not leak them into other tests.
+func restoreRegistry(t *testing.T) {
+	t.Helper()
+	registryMu.RLock()
+	savedFactories, savedAliases, savedExtensions := maps.Clone(factories), maps.Clone(aliases), maps.Clone(extensions)
+	registryMu.RUnlock()
+
+	t.Cleanup(func() {
+		registryMu.Lock()
+		defer registryMu.Unlock()
+		factories, aliases, extensions = savedFactories, savedAliases, savedExtensions
+	})
+}
+
+func TestRegister(t *testing.T) {
+	restoreRegistry(t)
+	Register("echo", scriptRunnerFactory("echo"))
+	RegisterAlias("say", "echo")
+	RegisterExtension("txt", "echo")
+
+	for _, interop := range []Interop{
+		{Language: "echo", FilePath: "hello.txt"},
+		{Language: "SAY", FilePath: "hello.txt"},
+		{FilePath: "hello.txt"},
+	} {
+		got, err := NewInteropRunner(interop).Run()
+		if err != nil {
+			t.Fatalf("Runner.Run() error = %v", err)
+		}
+		if want := getAbs("hello.txt") + "\n"; got != want {
+			t.Errorf("Runner.Run() = %q, want %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package interop
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// versionTimeout bounds how long probing an interpreter for its version may take
+const versionTimeout = 10 * time.Second
+
+// interpreterSpec tells the resolver where a language keeps its interpreters
+// and the runners how to feed it inline source
+type interpreterSpec struct {
+	// versionArgs print the interpreter version, defaults to --version
+	versionArgs []string
+	// venv enables virtualenv lookup
+	venv bool
+	// versionFile pins a version for the directory tree it is in, e.g. .nvmrc
+	versionFile string
+	// installed returns the candidate binaries for a pinned version
+	installed func(pinned string) []string
+	// stdinArg makes the interpreter read the script from stdin, e.g. "-"
+	stdinArg string
+	// ext is the extension of temp files holding inline source
+	ext string
+}
+
+var interpreterSpecs = map[string]interpreterSpec{
+	"python3": {venv: true, versionFile: ".python-version", installed: pyenvInterpreters, stdinArg: "-", ext: ".py"},
+	"node":    {versionFile: ".nvmrc", installed: nvmInterpreters, stdinArg: "-", ext: ".js"},
+	"java":    {versionArgs: []string{"-version"}, ext: ".java"},
+	"lua":     {versionArgs: []string{"-v"}, stdinArg: "-", ext: ".lua"},
+	"ruby":    {stdinArg: "-", ext: ".rb"},
+	"perl":    {stdinArg: "-", ext: ".pl"},
+	"bash":    {stdinArg: "-s", ext: ".sh"},
+	"deno":    {stdinArg: "-", ext: ".ts"},
+	"php":     {ext: ".php"},
+	"bun":     {ext: ".js"},
+}
+
+// versionCache remembers probed versions per binary path and modification time
+var versionCache sync.Map
+
+// interpreterResolver is implemented by the built-in runners to find their
+// interpreter without running the script
+type interpreterResolver interface {
+	resolveInterpreter() (string, error)
+}
+
+// ResolveInterpreter finds the binary that runs the script of i, name is the
+// interpreter the runner uses by default such as "python3" or "node".
+//
+// Interop.Interpreter wins when set. Otherwise an active or adjacent virtualenv
+// (python), then the version pinned by a .python-version or .nvmrc file found
+// next to the script or in one of its parents, then PATH are searched.
+// When Interop.Version is set the interpreter must satisfy it.
+func ResolveInterpreter(i Interop, name string) (string, error) {
+	spec := interpreterSpecs[name]
+
+	var constraint versionConstraint
+	if i.Version != "" {
+		var err error
+		if constraint, err = parseVersionConstraint(i.Version); err != nil {
+			return "", &InterpreterError{Name: name, Constraint: i.Version, Err: err}
+		}
+	}
+
+	path, searched := findInterpreter(i, name, spec)
+	if path == "" {
+		return "", &InterpreterError{Name: name, Searched: searched, Err: ErrInterpreterNotFound}
+	}
+
+	if constraint == nil {
+		return path, nil
+	}
+
+	v, err := interpreterVersion(path, spec.versionArgs)
+	if err != nil {
+		return "", &InterpreterError{Name: name, Path: path, Err: err}
+	}
+	if !constraint.check(v) {
+		return "", &InterpreterError{Name: name, Path: path, Version: v.String(), Constraint: i.Version, Err: ErrInterpreterVersion}
+	}
+
+	return path, nil
+}
+
+// findInterpreter returns the first usable candidate and every location it looked at
+func findInterpreter(i Interop, name string, spec interpreterSpec) (string, []string) {
+	var searched []string
+
+	if i.Interpreter != "" {
+		searched = append(searched, i.Interpreter)
+		if path, err := exec.LookPath(i.Interpreter); err == nil {
+			return path, searched
+		}
+		return "", searched
+	}
+
+	scriptDir := "."
+	if i.FilePath != "" {
+		scriptDir = filepath.Dir(i.FilePath)
+	}
+	if abs, err := filepath.Abs(scriptDir); err == nil {
+		scriptDir = abs
+	}
+
+	var candidates []string
+	if spec.venv {
+		if venv := os.Getenv("VIRTUAL_ENV"); venv != "" {
+			candidates = append(candidates, venvPython(venv))
+		}
+		for dir := scriptDir; ; dir = filepath.Dir(dir) {
+			candidates = append(candidates, venvPython(filepath.Join(dir, ".venv")), venvPython(filepath.Join(dir, "venv")))
+			if filepath.Dir(dir) == dir {
+				break
+			}
+		}
+	}
+
+	if spec.versionFile != "" {
+		if pinned, ok := findPinnedVersion(scriptDir, spec.versionFile); ok {
+			candidates = append(candidates, spec.installed(pinned)...)
+		}
+	}
+
+	for _, candidate := range candidates {
+		searched = append(searched, candidate)
+		if isExecutable(candidate) {
+			return candidate, searched
+		}
+	}
+
+	searched = append(searched, "PATH")
+	if path, err := exec.LookPath(name); err == nil {
+		return path, searched
+	}
+
+	return "", searched
+}
+
+// interpreterVersion runs the interpreter with its version flag and parses the output
+func interpreterVersion(path string, args []string) (version, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	key := fmt.Sprintf("%s@%d", path, info.ModTime().UnixNano())
+	if v, ok := versionCache.Load(key); ok {
+		return v.(version), nil
+	}
+
+	if len(args) == 0 {
+		args = []string{"--version"}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), versionTimeout)
+	defer cancel()
+
+	// some interpreters, e.g. java, print their version to stderr
+	out, err := exec.CommandContext(ctx, path, args...).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get version of %s: %v", path, err)
+	}
+
+	v, err := findVersion(string(out))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get version of %s: %v", path, err)
+	}
+
+	versionCache.Store(key, v)
+	return v, nil
+}
+
+// findPinnedVersion reads the first line of the nearest versionFile in dir or its parents
+func findPinnedVersion(dir string, versionFile string) (string, bool) {
+	for {
+		if b, err := os.ReadFile(filepath.Join(dir, versionFile)); err == nil {
+			for _, line := range strings.Split(string(b), "\n") {
+				if line = strings.TrimSpace(line); line != "" {
+					return line, true
+				}
+			}
+			return "", false
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+func pyenvInterpreters(pinned string) []string {
+	root := os.Getenv("PYENV_ROOT")
+	if root == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil
+		}
+		root = filepath.Join(home, ".pyenv")
+	}
+
+	var candidates []string
+	for _, dir := range installedVersions(filepath.Join(root, "versions"), "", pinned) {
+		candidates = append(candidates, filepath.Join(dir, "bin", "python3"), filepath.Join(dir, "bin", "python"))
+	}
+	return candidates
+}
+
+func nvmInterpreters(pinned string) []string {
+	root := os.Getenv("NVM_DIR")
+	if root == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil
+		}
+		root = filepath.Join(home, ".nvm")
+	}
+
+	var candidates []string
+	for _, dir := range installedVersions(filepath.Join(root, "versions", "node"), "v", pinned) {
+		candidates = append(candidates, filepath.Join(dir, "bin", "node"))
+	}
+	return candidates
+}
+
+// installedVersions lists the directories in versionsDir named prefix+version that
+// match the possibly partial pinned version, newest first. Aliases such as
+// "system" or "lts/*" match nothing.
+func installedVersions(versionsDir string, prefix string, pinned string) []string {
+	want, err := parseVersion(pinned)
+	if err != nil {
+		return nil
+	}
+
+	entries, err := os.ReadDir(versionsDir)
+	if err != nil {
+		return nil
+	}
+
+	type installed struct {
+		dir string
+		v   version
+	}
+	var matches []installed
+	for _, entry := range entries {
+		name, ok := strings.CutPrefix(entry.Name(), prefix)
+		if !ok {
+			continue
+		}
+		v, err := parseVersion(name)
+		if err != nil || !v.hasPrefix(want) {
+			continue
+		}
+		matches = append(matches, installed{dir: filepath.Join(versionsDir, entry.Name()), v: v})
+	}
+
+	sort.Slice(matches, func(a, b int) bool {
+		return matches[a].v.compare(matches[b].v) > 0
+	})
+
+	dirs := make([]string, len(matches))
+	for i, m := range matches {
+		dirs[i] = m.dir
+	}
+	return dirs
+}
+
+func venvPython(venv string) string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(venv, "Scripts", "python.exe")
+	}
+	return filepath.Join(venv, "bin", "python")
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	return runtime.GOOS == "windows" || info.Mode()&0111 != 0
+}
@@ -0,0 +1,154 @@
+package interop
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestVersionConstraint(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		want       bool
+	}{
+		{constraint: "3.8", version: "3.11.4", want: true},
+		{constraint: "3.8", version: "3.6.9", want: false},
+		{constraint: ">=18, <21", version: "20.1.0", want: true},
+		{constraint: ">=18, <21", version: "21.0.0", want: false},
+		{constraint: "=3.10", version: "3.10.12", want: true},
+		{constraint: "==3.10", version: "3.11.0", want: false},
+		{constraint: "!=3.10", version: "3.11.0", want: true},
+		{constraint: ">1.8", version: "1.8.0", want: false},
+		{constraint: "<=v17", version: "17.0.0", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.constraint+" "+tt.version, func(t *testing.T) {
+			c, err := parseVersionConstraint(tt.constraint)
+			if err != nil {
+				t.Fatalf("parseVersionConstraint() error = %v", err)
+			}
+			v, err := parseVersion(tt.version)
+			if err != nil {
+				t.Fatalf("parseVersion() error = %v", err)
+			}
+			if got := c.check(v); got != tt.want {
+				t.Errorf("check() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindVersion(t *testing.T) {
+	tests := map[string]string{
+		"Python 3.11.4":                 "3.11.4",
+		"v20.1.0":                       "20.1.0",
+		`openjdk version "17.0.2" 2022`: "17.0.2",
+		"This is perl 5, version 34, subversion 0 (v5.34.0)": "5.34.0",
+		"GNU bash, version 5.1.16(1)-release":                "5.1.16",
+		`java version "21" 2023-09-19 LTS`:                   "21",
+		"openjdk 21 2023-09-19":                              "21",
+	}
+	for output, want := range tests {
+		v, err := findVersion(output)
+		if err != nil {
+			t.Errorf("findVersion(%q) error = %v", output, err)
+			continue
+		}
+		if got := v.String(); got != want {
+			t.Errorf("findVersion(%q) = %v, want %v", output, got, want)
+		}
+	}
+}
+
+func TestResolveInterpreter(t *testing.T) {
+	python, err := exec.LookPath("python3")
+	if err != nil {
+		t.Skip("python3 not found in PATH")
+	}
+
+	t.Run("virtualenv next to the script", func(t *testing.T) {
+		dir := t.TempDir()
+		venvBin := filepath.Join(dir, ".venv", "bin")
+		if err := os.MkdirAll(venvBin, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(python, filepath.Join(venvBin, "python")); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv("VIRTUAL_ENV", "")
+
+		got, err := ResolveInterpreter(Interop{FilePath: filepath.Join(dir, "scripts", "main.py")}, "python3")
+		if err != nil {
+			t.Fatalf("ResolveInterpreter() error = %v", err)
+		}
+		if want := filepath.Join(venvBin, "python"); got != want {
+			t.Errorf("ResolveInterpreter() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("pinned by .nvmrc", func(t *testing.T) {
+		dir := t.TempDir()
+		nvm := filepath.Join(dir, "nvm")
+		for _, v := range []string{"v18.1.0", "v18.17.0", "v20.0.0"} {
+			bin := filepath.Join(nvm, "versions", "node", v, "bin")
+			if err := os.MkdirAll(bin, 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(bin, "node"), []byte("#!/bin/sh\n"), 0755); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := os.WriteFile(filepath.Join(dir, ".nvmrc"), []byte("v18\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv("NVM_DIR", nvm)
+
+		got, err := ResolveInterpreter(Interop{FilePath: filepath.Join(dir, "main.js")}, "node")
+		if err != nil {
+			t.Fatalf("ResolveInterpreter() error = %v", err)
+		}
+		if want := filepath.Join(nvm, "versions", "node", "v18.17.0", "bin", "node"); got != want {
+			t.Errorf("ResolveInterpreter() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("explicit interpreter", func(t *testing.T) {
+		got, err := ResolveInterpreter(Interop{Interpreter: python}, "python3")
+		if err != nil || got != python {
+			t.Errorf("ResolveInterpreter() = %v, %v, want %v", got, err, python)
+		}
+	})
+
+	t.Run("missing interpreter", func(t *testing.T) {
+		_, err := ResolveInterpreter(Interop{Interpreter: "helpme-no-such-interpreter"}, "python3")
+		var interpreterErr *InterpreterError
+		if !errors.As(err, &interpreterErr) || !errors.Is(err, ErrInterpreterNotFound) {
+			t.Errorf("ResolveInterpreter() error = %v, want ErrInterpreterNotFound", err)
+		}
+	})
+
+	t.Run("version too old", func(t *testing.T) {
+		_, err := NewInteropRunner(Interop{
+			Language: "python",
+			FilePath: getAbs(TEST_DATA_PATH, "example.py"),
+			Version:  ">=999",
+		}).Run()
+		if !errors.Is(err, ErrInterpreterVersion) {
+			t.Errorf("Runner.Run() error = %v, want ErrInterpreterVersion", err)
+		}
+	})
+
+	t.Run("version satisfied", func(t *testing.T) {
+		got, err := NewInteropRunner(Interop{
+			Language: "python",
+			FilePath: getAbs(TEST_DATA_PATH, "example.py"),
+			Version:  "3",
+		}).Run()
+		if err != nil || got != "Hello World from Python" {
+			t.Errorf("Runner.Run() = %v, %v", got, err)
+		}
+	})
+}
@@ -2,36 +2,170 @@ package interop
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 )
 
+// JavaRunner compiles the source with javac into a cache directory keyed by
+// the source hash, then runs the class with java. Interop.Timeout applies to
+// each of the two phases separately.
 type JavaRunner struct{ I Interop }
 
 func (runner *JavaRunner) Run() (string, error) {
-	// Get absolute path
-	absPath, err := filepath.Abs(runner.I.FilePath)
+	return runner.RunContext(context.Background())
+}
+
+func (runner *JavaRunner) RunContext(ctx context.Context) (string, error) {
+	return runBuffered(ctx, runner.RunStream)
+}
+
+func (runner *JavaRunner) RunStream(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) (*Result, error) {
+	absPath, className, cleanup, err := runner.source()
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	java, err := ResolveInterpreter(runner.I, "java")
+	if err != nil {
+		return nil, err
+	}
+
+	javac, err := javacFor(java)
+	if err != nil {
+		return nil, err
+	}
+
+	classDir, err := runner.compile(ctx, javac, absPath, className)
 	if err != nil {
-		return "", fmt.Errorf("failed to get absolute path: %v", err)
+		return nil, err
 	}
 
-	javaProgram, ok := strings.CutSuffix(absPath, ".java")
+	return runCommand(ctx, runner.I, java, append([]string{"-cp", classDir, className}, runner.I.Args...), stdin, stdout, stderr)
+}
+
+// resolveInterpreter finds java and the javac next to it
+func (runner *JavaRunner) resolveInterpreter() (string, error) {
+	java, err := ResolveInterpreter(runner.I, "java")
+	if err != nil {
+		return "", err
+	}
+	if _, err := javacFor(java); err != nil {
+		return "", err
+	}
+	return java, nil
+}
+
+// source returns the java file to compile and its class name,
+// inline source is written to a temp file named after its class.
+func (runner *JavaRunner) source() (string, string, func(), error) {
+	if runner.I.Source == nil {
+		// Get absolute path
+		absPath, err := filepath.Abs(runner.I.FilePath)
+		if err != nil {
+			return "", "", nil, fmt.Errorf("failed to get absolute path: %v", err)
+		}
 
+		className, ok := strings.CutSuffix(filepath.Base(absPath), ".java")
+		if !ok {
+			return "", "", nil, fmt.Errorf("file is not a java program")
+		}
+		return absPath, className, func() {}, nil
+	}
+
+	src, err := readSource(runner.I)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	className, ok := javaClassName(src)
 	if !ok {
-		return "", fmt.Errorf("file is not a java program")
+		return "", "", nil, fmt.Errorf("no class found in java source")
 	}
 
-	cmd := exec.Command("javac", absPath, "&&", "java", javaProgram)
+	absPath, cleanup, err := writeSource(bytes.NewReader(src), className+".java")
+	if err != nil {
+		return "", "", nil, err
+	}
+	return absPath, className, cleanup, nil
+}
+
+// compile compiles absPath unless a previous compilation of the same source
+// is already cached, and returns the directory holding the class files.
+func (runner *JavaRunner) compile(ctx context.Context, javac string, absPath string, className string) (string, error) {
+	src, err := os.ReadFile(absPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read source: %v", err)
+	}
+
+	classDir := javaClassDir(javac, className, src)
+	if _, err := os.Stat(filepath.Join(classDir, className+".class")); err == nil {
+		return classDir, nil
+	}
 
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	if err := os.MkdirAll(filepath.Dir(classDir), 0755); err != nil {
+		return "", fmt.Errorf("failed to create cache directory: %v", err)
+	}
+
+	// compile into a scratch directory first so a concurrent run never sees a half written cache entry
+	tmpDir, err := os.MkdirTemp(filepath.Dir(classDir), "javac-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if _, err := runCommand(ctx, runner.I, javac, []string{"-d", tmpDir, absPath}, nil, nil, nil); err != nil {
+		var exitErr *ExitError
+		if errors.As(err, &exitErr) {
+			return "", &CompileError{Diagnostics: exitErr.Stderr}
+		}
+		return "", err
+	}
 
-	if err := cmd.Run(); err != nil {
-		return "", fmt.Errorf("failed to run script: %v\nstderr: %s", err, stderr.String())
+	if err := os.Rename(tmpDir, classDir); err != nil {
+		// another run may have populated the cache in the meantime
+		if _, statErr := os.Stat(filepath.Join(classDir, className+".class")); statErr != nil {
+			return "", fmt.Errorf("failed to store compiled classes: %v", err)
+		}
 	}
 
-	return stdout.String(), nil
+	return classDir, nil
+}
+
+// javacFor returns the javac that belongs to the same JDK as java
+func javacFor(java string) (string, error) {
+	if javac := filepath.Join(filepath.Dir(java), "javac"); isExecutable(javac) {
+		return javac, nil
+	}
+
+	javac, err := exec.LookPath("javac")
+	if err != nil {
+		return "", &InterpreterError{Name: "javac", Searched: []string{filepath.Dir(java), "PATH"}, Err: ErrInterpreterNotFound}
+	}
+	return javac, nil
+}
+
+// javaClassDir returns the cache directory for the classes javac compiled from src
+func javaClassDir(javac string, className string, src []byte) string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		cacheDir = os.TempDir()
+	}
+
+	h := sha256.New()
+	h.Write([]byte(javac))
+	h.Write([]byte{0})
+	h.Write([]byte(className))
+	h.Write([]byte{0})
+	h.Write(src)
+
+	return filepath.Join(cacheDir, "helpme", "interop", "java", hex.EncodeToString(h.Sum(nil)))
 }
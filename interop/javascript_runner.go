@@ -1,30 +1,24 @@
 package interop
 
 import (
-	"bytes"
-	"fmt"
-	"os/exec"
-	"path/filepath"
+	"context"
+	"io"
 )
 
 type JavascriptRunner struct{ I Interop }
 
 func (runner *JavascriptRunner) Run() (string, error) {
-	// Get absolute path
-	absPath, err := filepath.Abs(runner.I.FilePath)
-	if err != nil {
-		return "", fmt.Errorf("failed to get absolute path: %v", err)
-	}
-
-	cmd := exec.Command("node", append([]string{absPath}, runner.I.Args...)...)
+	return runner.RunContext(context.Background())
+}
 
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+func (runner *JavascriptRunner) RunContext(ctx context.Context) (string, error) {
+	return runBuffered(ctx, runner.RunStream)
+}
 
-	if err := cmd.Run(); err != nil {
-		return "", fmt.Errorf("failed to run script: %v\nstderr: %s", err, stderr.String())
-	}
+func (runner *JavascriptRunner) resolveInterpreter() (string, error) {
+	return ResolveInterpreter(runner.I, "node")
+}
 
-	return stdout.String(), nil
+func (runner *JavascriptRunner) RunStream(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) (*Result, error) {
+	return runScript(ctx, runner.I, "node", nil, stdin, stdout, stderr)
 }
@@ -0,0 +1,229 @@
+package interop
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrPoolClosed is returned by Pool.Call after the pool was closed
+var ErrPoolClosed = errors.New("interop pool is closed")
+
+// PoolStats is a snapshot of the pool metrics
+type PoolStats struct {
+	// Size is the number of workers the pool keeps
+	Size int
+	// Busy is the number of workers serving a call
+	Busy int
+	// QueueDepth is the number of calls waiting for a free worker
+	QueueDepth int
+	// Calls is the number of calls served so far
+	Calls int64
+	// Restarts is the number of workers replaced after they crashed or a call was canceled
+	Restarts int64
+}
+
+// Pool keeps warm JSON-RPC workers of a single script, see RPCClient for the protocol.
+// Every worker serves one call at a time, a crashed worker is restarted on its next use.
+type Pool struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	interop Interop
+	source  []byte
+	size    int
+
+	idle chan *poolWorker
+
+	mu       sync.Mutex
+	handlers map[string]RPCHandler
+	workers  []*poolWorker
+	closed   bool
+
+	busy     atomic.Int64
+	waiting  atomic.Int64
+	calls    atomic.Int64
+	restarts atomic.Int64
+}
+
+type poolWorker struct {
+	client *RPCClient
+}
+
+// NewPool starts size workers of interop
+func NewPool(ctx context.Context, interop Interop, size int) (*Pool, error) {
+	if size <= 0 {
+		size = 1
+	}
+
+	// inline source is replayed for every worker
+	source, err := readSource(interop)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	p := &Pool{
+		ctx:      ctx,
+		cancel:   cancel,
+		interop:  interop,
+		source:   source,
+		size:     size,
+		idle:     make(chan *poolWorker, size),
+		handlers: map[string]RPCHandler{},
+	}
+
+	for n := 0; n < size; n++ {
+		w := &poolWorker{}
+		if err := p.start(w); err != nil {
+			p.Close()
+			return nil, err
+		}
+		p.workers = append(p.workers, w)
+		p.idle <- w
+	}
+
+	return p, nil
+}
+
+// Handle registers a method every worker can call back into Go
+func (p *Pool) Handle(method string, handler RPCHandler) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.handlers[method] = handler
+	for _, w := range p.workers {
+		if w.client != nil {
+			w.client.Handle(method, handler)
+		}
+	}
+}
+
+// Call invokes method on the next free worker, waiting for one when all are busy.
+// A worker that died while idle is restarted before the call is sent, only a
+// worker crashing during the call fails it and is restarted for the next call.
+// A worker whose call was canceled by ctx is killed and restarted the same way.
+func (p *Pool) Call(ctx context.Context, method string, result any, params ...any) error {
+	p.waiting.Add(1)
+	var w *poolWorker
+	select {
+	case w = <-p.idle:
+		p.waiting.Add(-1)
+	case <-ctx.Done():
+		p.waiting.Add(-1)
+		return ctx.Err()
+	case <-p.ctx.Done():
+		p.waiting.Add(-1)
+		return ErrPoolClosed
+	}
+
+	if p.ctx.Err() != nil {
+		p.idle <- w
+		return ErrPoolClosed
+	}
+
+	p.busy.Add(1)
+	defer func() {
+		p.busy.Add(-1)
+		p.idle <- w
+	}()
+
+	p.mu.Lock()
+	client := w.client
+	p.mu.Unlock()
+
+	if client != nil && client.isClosed() {
+		client.Close()
+		client = nil
+	}
+	if client == nil {
+		if err := p.start(w); err != nil {
+			return err
+		}
+		p.restarts.Add(1)
+
+		p.mu.Lock()
+		client = w.client
+		p.mu.Unlock()
+	}
+
+	p.calls.Add(1)
+	err := client.Call(ctx, method, result, params...)
+	switch {
+	case errors.Is(err, ErrRPCClosed):
+		client.Close()
+	case err != nil && ctx.Err() != nil && errors.Is(err, ctx.Err()):
+		// the worker is still busy with the abandoned call, the next caller
+		// would wait for it or read its late answer
+		client.cancel()
+		client.Close()
+	default:
+		return err
+	}
+
+	p.mu.Lock()
+	w.client = nil
+	p.mu.Unlock()
+	return err
+}
+
+// Stats returns the current pool metrics
+func (p *Pool) Stats() PoolStats {
+	return PoolStats{
+		Size:       p.size,
+		Busy:       int(p.busy.Load()),
+		QueueDepth: int(p.waiting.Load()),
+		Calls:      p.calls.Load(),
+		Restarts:   p.restarts.Load(),
+	}
+}
+
+// Close stops every worker, calls waiting for a worker fail with ErrPoolClosed
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	var clients []*RPCClient
+	for _, w := range p.workers {
+		if w.client != nil {
+			clients = append(clients, w.client)
+		}
+	}
+	p.mu.Unlock()
+
+	p.cancel()
+
+	var errs []error
+	for _, client := range clients {
+		if err := client.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// start launches a fresh worker process for w
+func (p *Pool) start(w *poolWorker) error {
+	interop := p.interop
+	if p.source != nil {
+		interop.Source = bytes.NewReader(p.source)
+	}
+
+	client, err := NewRPCClient(p.ctx, interop)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for method, handler := range p.handlers {
+		client.Handle(method, handler)
+	}
+	w.client = client
+	return nil
+}
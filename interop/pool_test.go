@@ -0,0 +1,168 @@
+package interop
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPool(t *testing.T) {
+	ctx := context.Background()
+	pool, err := NewPool(ctx, Interop{
+		Language: "python",
+		FilePath: getAbs(TEST_DATA_PATH, "rpc_worker.py"),
+	}, 3)
+	if err != nil {
+		t.Fatalf("NewPool() error = %v", err)
+	}
+	defer pool.Close()
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		pids = map[int]bool{}
+	)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var sum int
+			if err := pool.Call(ctx, "add", &sum, i, i); err != nil {
+				t.Errorf("Pool.Call(add) error = %v", err)
+				return
+			}
+			if sum != 2*i {
+				t.Errorf("Pool.Call(add) = %d, want %d", sum, 2*i)
+			}
+
+			var pid int
+			if err := pool.Call(ctx, "pid", &pid); err != nil {
+				t.Errorf("Pool.Call(pid) error = %v", err)
+				return
+			}
+			mu.Lock()
+			pids[pid] = true
+			mu.Unlock()
+		}(i)
+	}
+	wg.Wait()
+
+	if len(pids) > 3 {
+		t.Errorf("pool used %d worker processes, want at most 3", len(pids))
+	}
+
+	stats := pool.Stats()
+	if stats.Size != 3 || stats.Busy != 0 || stats.QueueDepth != 0 || stats.Calls != 100 {
+		t.Errorf("Pool.Stats() = %+v", stats)
+	}
+}
+
+func TestPoolRecycle(t *testing.T) {
+	ctx := context.Background()
+	pool, err := NewPool(ctx, Interop{
+		Language: "python",
+		FilePath: getAbs(TEST_DATA_PATH, "rpc_worker.py"),
+	}, 1)
+	if err != nil {
+		t.Fatalf("NewPool() error = %v", err)
+	}
+	defer pool.Close()
+
+	if err := pool.Call(ctx, "crash", nil); !errors.Is(err, ErrRPCClosed) {
+		t.Fatalf("Pool.Call(crash) error = %v, want ErrRPCClosed", err)
+	}
+
+	var sum int
+	if err := pool.Call(ctx, "add", &sum, 1, 2); err != nil || sum != 3 {
+		t.Fatalf("Pool.Call(add) after crash = %d, %v", sum, err)
+	}
+	if restarts := pool.Stats().Restarts; restarts != 1 {
+		t.Errorf("Pool.Stats().Restarts = %d, want 1", restarts)
+	}
+
+	if err := pool.Close(); err != nil {
+		t.Errorf("Pool.Close() error = %v", err)
+	}
+	if err := pool.Call(ctx, "add", nil, 1, 2); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("Pool.Call() after Close error = %v, want ErrPoolClosed", err)
+	}
+}
+
+func TestPoolRestartsIdleWorker(t *testing.T) {
+	ctx := context.Background()
+	pool, err := NewPool(ctx, Interop{
+		Language: "python",
+		FilePath: getAbs(TEST_DATA_PATH, "rpc_worker.py"),
+	}, 1)
+	if err != nil {
+		t.Fatalf("NewPool() error = %v", err)
+	}
+	defer pool.Close()
+
+	var pid int
+	if err := pool.Call(ctx, "pid", &pid); err != nil {
+		t.Fatalf("Pool.Call(pid) error = %v", err)
+	}
+
+	// kill the worker while it sits idle and wait until the client noticed
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := proc.Kill(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-pool.workers[0].client.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("worker client did not notice the killed process")
+	}
+
+	var sum int
+	if err := pool.Call(ctx, "add", &sum, 1, 2); err != nil || sum != 3 {
+		t.Fatalf("Pool.Call(add) after the idle worker died = %d, %v", sum, err)
+	}
+	if restarts := pool.Stats().Restarts; restarts != 1 {
+		t.Errorf("Pool.Stats().Restarts = %d, want 1", restarts)
+	}
+}
+
+func TestPoolRecycleCanceledCall(t *testing.T) {
+	ctx := context.Background()
+	pool, err := NewPool(ctx, Interop{
+		Language: "python",
+		FilePath: getAbs(TEST_DATA_PATH, "rpc_worker.py"),
+	}, 1)
+	if err != nil {
+		t.Fatalf("NewPool() error = %v", err)
+	}
+	defer pool.Close()
+
+	var before int
+	if err := pool.Call(ctx, "pid", &before); err != nil {
+		t.Fatalf("Pool.Call(pid) error = %v", err)
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+	if err := pool.Call(callCtx, "sleep", nil, 30); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Pool.Call(sleep) error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// the worker still sleeping must not serve the next call
+	callCtx, cancel = context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	var after int
+	if err := pool.Call(callCtx, "pid", &after); err != nil {
+		t.Fatalf("Pool.Call(pid) after a canceled call error = %v", err)
+	}
+	if after == before {
+		t.Errorf("Pool.Call(pid) = %d, want a new worker", after)
+	}
+	if restarts := pool.Stats().Restarts; restarts != 1 {
+		t.Errorf("Pool.Stats().Restarts = %d, want 1", restarts)
+	}
+}
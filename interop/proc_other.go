@@ -0,0 +1,9 @@
+//go:build !unix
+
+package interop
+
+import "os/exec"
+
+// setProcessGroup is a no-op on platforms without process groups,
+// cancellation only kills the direct child there.
+func setProcessGroup(cmd *exec.Cmd) {}
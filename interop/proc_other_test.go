@@ -0,0 +1,8 @@
+//go:build !unix
+
+package interop
+
+import "testing"
+
+// waitProcessGroupGone is a no-op on platforms without process groups
+func waitProcessGroupGone(t *testing.T, pgid int) {}
@@ -0,0 +1,28 @@
+//go:build unix
+
+package interop
+
+import (
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// waitProcessGroupGone fails the test unless every process of the group is
+// gone within a few seconds of the runner returning.
+func waitProcessGroupGone(t *testing.T, pgid int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		err := syscall.Kill(-pgid, 0)
+		if errors.Is(err, syscall.ESRCH) {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Errorf("process group %d still exists: kill(-pgid, 0) = %v", pgid, err)
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
@@ -1,30 +1,24 @@
 package interop
 
 import (
-	"bytes"
-	"fmt"
-	"os/exec"
-	"path/filepath"
+	"context"
+	"io"
 )
 
 type PythonRunner struct{ I Interop }
 
 func (runner *PythonRunner) Run() (string, error) {
-	// Get absolute path
-	absPath, err := filepath.Abs(runner.I.FilePath)
-	if err != nil {
-		return "", fmt.Errorf("failed to get absolute path: %v", err)
-	}
-
-	cmd := exec.Command("python3", append([]string{absPath}, runner.I.Args...)...)
+	return runner.RunContext(context.Background())
+}
 
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+func (runner *PythonRunner) RunContext(ctx context.Context) (string, error) {
+	return runBuffered(ctx, runner.RunStream)
+}
 
-	if err := cmd.Run(); err != nil {
-		return "", fmt.Errorf("failed to run script: %v\nstderr: %s", err, stderr.String())
-	}
+func (runner *PythonRunner) resolveInterpreter() (string, error) {
+	return ResolveInterpreter(runner.I, "python3")
+}
 
-	return stdout.String(), nil
+func (runner *PythonRunner) RunStream(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) (*Result, error) {
+	return runScript(ctx, runner.I, "python3", nil, stdin, stdout, stderr)
 }
@@ -0,0 +1,142 @@
+package interop
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// RunnerFactory creates the InteropRunner for a language
+type RunnerFactory func(interop Interop) InteropRunner
+
+var (
+	registryMu sync.RWMutex
+	factories  = map[string]RunnerFactory{}
+	aliases    = map[string]string{}
+	extensions = map[string]string{}
+)
+
+func init() {
+	Register("javascript", func(i Interop) InteropRunner { return &JavascriptRunner{I: i} })
+	RegisterAlias("js", "javascript")
+	RegisterAlias("node", "javascript")
+	RegisterExtension(".js", "javascript")
+	RegisterExtension(".mjs", "javascript")
+	RegisterExtension(".cjs", "javascript")
+
+	Register("python", func(i Interop) InteropRunner { return &PythonRunner{I: i} })
+	RegisterAlias("py", "python")
+	RegisterAlias("python3", "python")
+	RegisterExtension(".py", "python")
+
+	Register("java", func(i Interop) InteropRunner { return &JavaRunner{I: i} })
+	RegisterExtension(".java", "java")
+
+	Register("ruby", scriptRunnerFactory("ruby"))
+	RegisterAlias("rb", "ruby")
+	RegisterExtension(".rb", "ruby")
+
+	Register("bash", scriptRunnerFactory("bash"))
+	RegisterAlias("sh", "bash")
+	RegisterExtension(".sh", "bash")
+	RegisterExtension(".bash", "bash")
+
+	Register("perl", scriptRunnerFactory("perl"))
+	RegisterAlias("pl", "perl")
+	RegisterExtension(".pl", "perl")
+
+	Register("php", scriptRunnerFactory("php"))
+	RegisterExtension(".php", "php")
+
+	Register("lua", scriptRunnerFactory("lua"))
+	RegisterExtension(".lua", "lua")
+
+	Register("deno", scriptRunnerFactory("deno", "run"))
+	RegisterAlias("typescript", "deno")
+	RegisterAlias("ts", "deno")
+	RegisterExtension(".ts", "deno")
+
+	Register("bun", scriptRunnerFactory("bun", "run"))
+}
+
+// Register makes a language available to NewInteropRunner.
+// Registering an already known language replaces its factory.
+func Register(lang string, factory RunnerFactory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	factories[strings.ToLower(lang)] = factory
+}
+
+// RegisterAlias makes alias resolve to the registered language lang
+func RegisterAlias(alias string, lang string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	aliases[strings.ToLower(alias)] = strings.ToLower(lang)
+}
+
+// RegisterExtension maps a file extension such as ".rb" to lang, it is used
+// to detect the language when Interop.Language is empty.
+func RegisterExtension(ext string, lang string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	extensions[strings.ToLower(ext)] = strings.ToLower(lang)
+}
+
+// Languages returns the names of all registered languages
+func Languages() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	langs := make([]string, 0, len(factories))
+	for lang := range factories {
+		langs = append(langs, lang)
+	}
+	return langs
+}
+
+// languageOf returns the registered language name interop resolves to, empty when unsupported
+func languageOf(interop Interop) string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	lang := resolveLanguage(interop)
+	if _, ok := factories[lang]; !ok {
+		return ""
+	}
+	return lang
+}
+
+// lookupFactory resolves the language of interop, falling back to the
+// extension of FilePath when no language is set.
+func lookupFactory(interop Interop) (RunnerFactory, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	factory, ok := factories[resolveLanguage(interop)]
+	return factory, ok
+}
+
+// resolveLanguage maps the language or file extension of interop to a
+// registered name, callers must hold registryMu.
+func resolveLanguage(interop Interop) string {
+	lang := strings.ToLower(interop.Language)
+	if lang == "" {
+		lang = extensions[strings.ToLower(filepath.Ext(interop.FilePath))]
+	}
+	if name, ok := aliases[lang]; ok {
+		lang = name
+	}
+	return lang
+}
+
+func scriptRunnerFactory(interpreter string, flags ...string) RunnerFactory {
+	return func(i Interop) InteropRunner {
+		return &ScriptRunner{I: i, Interpreter: interpreter, Flags: flags}
+	}
+}
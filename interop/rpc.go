@@ -0,0 +1,318 @@
+package interop
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// rpcCloseTimeout bounds how long Close waits for the worker to exit after its stdin was closed
+const rpcCloseTimeout = 5 * time.Second
+
+// JSON-RPC 2.0 error codes
+const (
+	RPCParseError     = -32700
+	RPCInvalidRequest = -32600
+	RPCMethodNotFound = -32601
+	RPCInvalidParams  = -32602
+	RPCInternalError  = -32603
+)
+
+// ErrRPCClosed is returned by Call once the worker has exited or the client was closed
+var ErrRPCClosed = errors.New("rpc worker is closed")
+
+// RPCError is an error object sent by the other side of a JSON-RPC connection
+type RPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// RPCHandler serves a method the script calls back into Go.
+// params holds the raw JSON params of the request.
+type RPCHandler func(ctx context.Context, params json.RawMessage) (any, error)
+
+type rpcMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+}
+
+// RPCClient talks JSON-RPC 2.0 to a long-lived script worker over its stdin and stdout,
+// one JSON message per line. Calls go both ways: Go calls functions the worker exposes
+// and the worker may call methods registered with Handle.
+// The worker must keep stdout for the protocol and log to stderr, the shims under
+// test_data/interop_example take care of that.
+type RPCClient struct {
+	stdin  *io.PipeWriter
+	cancel context.CancelFunc
+	nextID atomic.Int64
+
+	writeMu sync.Mutex
+	enc     *json.Encoder
+
+	mu       sync.Mutex
+	pending  map[int64]chan rpcMessage
+	handlers map[string]RPCHandler
+
+	exited chan struct{}
+	done   chan struct{}
+	result *Result
+	err    error
+}
+
+// NewRPCClient starts interop as a JSON-RPC worker. The worker lives until Close
+// is called or ctx is done, Interop.Timeout bounds its whole lifetime.
+func NewRPCClient(ctx context.Context, interop Interop) (*RPCClient, error) {
+	runner := NewInteropRunner(interop)
+	if unknown, ok := runner.(*UnknownRunner); ok {
+		return nil, unknown.err()
+	}
+	// a missing interpreter fails here rather than on the first Call
+	if r, ok := runner.(interpreterResolver); ok {
+		if _, err := r.resolveInterpreter(); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	stdinR, stdinW := io.Pipe()
+	stdoutR, stdoutW := io.Pipe()
+
+	c := &RPCClient{
+		stdin:    stdinW,
+		cancel:   cancel,
+		enc:      json.NewEncoder(stdinW),
+		pending:  map[int64]chan rpcMessage{},
+		handlers: map[string]RPCHandler{},
+		exited:   make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	go func() {
+		res, err := runner.RunStream(ctx, stdinR, stdoutW, nil)
+		stdoutW.Close()
+		stdinR.Close()
+
+		c.mu.Lock()
+		c.result, c.err = res, err
+		c.mu.Unlock()
+		close(c.exited)
+	}()
+
+	go c.readLoop(ctx, stdoutR)
+
+	return c, nil
+}
+
+// Handle registers a method the worker can call back into Go
+func (c *RPCClient) Handle(method string, handler RPCHandler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.handlers[method] = handler
+}
+
+// Call invokes method on the worker with params as positional arguments
+// and decodes the returned value into result, which may be nil.
+func (c *RPCClient) Call(ctx context.Context, method string, result any, params ...any) error {
+	if params == nil {
+		params = []any{}
+	}
+	rawParams, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("failed to encode params: %v", err)
+	}
+
+	id := c.nextID.Add(1)
+	ch := make(chan rpcMessage, 1)
+
+	c.mu.Lock()
+	select {
+	case <-c.done:
+		c.mu.Unlock()
+		return c.closedErr()
+	default:
+	}
+	c.pending[id] = ch
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	if err := c.send(rpcMessage{
+		ID:     json.RawMessage(strconv.FormatInt(id, 10)),
+		Method: method,
+		Params: rawParams,
+	}); err != nil {
+		return c.closedErr()
+	}
+
+	select {
+	case msg := <-ch:
+		if msg.Error != nil {
+			return msg.Error
+		}
+		if result == nil || msg.Result == nil {
+			return nil
+		}
+		return json.Unmarshal(msg.Result, result)
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.done:
+		return c.closedErr()
+	}
+}
+
+// Close closes the worker stdin and waits for it to exit, killing it when it
+// does not exit within a few seconds. It returns the error the worker exited with.
+func (c *RPCClient) Close() error {
+	c.stdin.Close()
+
+	select {
+	case <-c.done:
+	case <-time.After(rpcCloseTimeout):
+		c.cancel()
+		<-c.done
+	}
+	c.cancel()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if errors.Is(c.err, ErrCanceled) {
+		return nil
+	}
+	return c.err
+}
+
+// isClosed reports whether the worker exited or stopped answering, a Call
+// would fail with ErrRPCClosed.
+func (c *RPCClient) isClosed() bool {
+	select {
+	case <-c.exited:
+		return true
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Result returns the result of the worker process once it has exited
+func (c *RPCClient) Result() *Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.result
+}
+
+func (c *RPCClient) closedErr() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		if c.result != nil {
+			return fmt.Errorf("%w: %w\nstderr: %s", ErrRPCClosed, c.err, c.result.Stderr)
+		}
+		return fmt.Errorf("%w: %w", ErrRPCClosed, c.err)
+	}
+	return ErrRPCClosed
+}
+
+func (c *RPCClient) send(msg rpcMessage) error {
+	msg.JSONRPC = "2.0"
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	return c.enc.Encode(msg)
+}
+
+func (c *RPCClient) readLoop(ctx context.Context, stdout io.Reader) {
+	defer close(c.done)
+
+	reader := bufio.NewReader(stdout)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			c.dispatch(ctx, line)
+		}
+		if err != nil {
+			// wait for the process result before waking up pending calls
+			<-c.exited
+			return
+		}
+	}
+}
+
+func (c *RPCClient) dispatch(ctx context.Context, line []byte) {
+	var msg rpcMessage
+	if err := json.Unmarshal(line, &msg); err != nil {
+		// not a protocol message, e.g. a stray print from the script
+		return
+	}
+
+	if msg.Method != "" {
+		go c.serve(ctx, msg)
+		return
+	}
+
+	id, err := strconv.ParseInt(string(msg.ID), 10, 64)
+	if err != nil {
+		return
+	}
+
+	// the first response wins, a duplicate or late one finds no pending call
+	// and never blocks the read loop on a channel nobody reads
+	c.mu.Lock()
+	ch, ok := c.pending[id]
+	delete(c.pending, id)
+	c.mu.Unlock()
+	if ok {
+		ch <- msg
+	}
+}
+
+// serve answers a request the worker sent to Go
+func (c *RPCClient) serve(ctx context.Context, req rpcMessage) {
+	c.mu.Lock()
+	handler, ok := c.handlers[req.Method]
+	c.mu.Unlock()
+
+	resp := rpcMessage{ID: req.ID}
+	if !ok {
+		resp.Error = &RPCError{Code: RPCMethodNotFound, Message: fmt.Sprintf("method %q not found", req.Method)}
+	} else if result, err := handler(ctx, req.Params); err != nil {
+		var rpcErr *RPCError
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &RPCError{Code: RPCInternalError, Message: err.Error()}
+		}
+		resp.Error = rpcErr
+	} else if resp.Result, err = json.Marshal(result); err != nil {
+		resp.Result = nil
+		resp.Error = &RPCError{Code: RPCInternalError, Message: fmt.Sprintf("failed to encode result: %v", err)}
+	}
+
+	// notifications carry no id and get no response
+	if req.ID == nil {
+		return
+	}
+	c.send(resp)
+}
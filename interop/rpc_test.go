@@ -0,0 +1,161 @@
+package interop
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRPCClient(t *testing.T) {
+	tests := []struct {
+		name      string
+		interop   Interop
+		wantGreet string
+	}{
+		{
+			name: "Test#PythonWorker",
+			interop: Interop{
+				Language: "python",
+				FilePath: getAbs(TEST_DATA_PATH, "rpc_worker.py"),
+			},
+			wantGreet: "Hello Go from Python",
+		},
+		{
+			name: "Test#JavascriptWorker",
+			interop: Interop{
+				Language: "javascript",
+				FilePath: getAbs(TEST_DATA_PATH, "rpc_worker.js"),
+			},
+			wantGreet: "Hello Go from Javascript",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			client, err := NewRPCClient(ctx, tt.interop)
+			if err != nil {
+				t.Fatalf("NewRPCClient() error = %v", err)
+			}
+			client.Handle("name", func(ctx context.Context, params json.RawMessage) (any, error) {
+				return "Go", nil
+			})
+
+			var wg sync.WaitGroup
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					var sum int
+					if err := client.Call(ctx, "add", &sum, i, 1); err != nil {
+						t.Errorf("Call(add) error = %v", err)
+						return
+					}
+					if sum != i+1 {
+						t.Errorf("Call(add) = %d, want %d", sum, i+1)
+					}
+				}(i)
+			}
+			wg.Wait()
+
+			var upper string
+			if err := client.Call(ctx, "upper", &upper, "hello"); err != nil || upper != "HELLO" {
+				t.Errorf("Call(upper) = %q, %v, want HELLO", upper, err)
+			}
+
+			var greet string
+			if err := client.Call(ctx, "greet", &greet); err != nil || greet != tt.wantGreet {
+				t.Errorf("Call(greet) = %q, %v, want %q", greet, err, tt.wantGreet)
+			}
+
+			var rpcErr *RPCError
+			if err := client.Call(ctx, "fail", nil); !errors.As(err, &rpcErr) || rpcErr.Code != RPCInternalError {
+				t.Errorf("Call(fail) error = %v, want RPCError %d", err, RPCInternalError)
+			}
+			if err := client.Call(ctx, "missing", nil); !errors.As(err, &rpcErr) || rpcErr.Code != RPCMethodNotFound {
+				t.Errorf("Call(missing) error = %v, want RPCError %d", err, RPCMethodNotFound)
+			}
+
+			if err := client.Close(); err != nil {
+				t.Errorf("Close() error = %v", err)
+			}
+			if err := client.Call(ctx, "add", nil, 1, 2); !errors.Is(err, ErrRPCClosed) {
+				t.Errorf("Call() after Close error = %v, want ErrRPCClosed", err)
+			}
+		})
+	}
+}
+
+func TestRPCClientWorkerExit(t *testing.T) {
+	client, err := NewRPCClient(context.Background(), Interop{
+		Language: "python",
+		FilePath: getAbs(TEST_DATA_PATH, "example.py"),
+	})
+	if err != nil {
+		t.Fatalf("NewRPCClient() error = %v", err)
+	}
+	defer client.Close()
+
+	if err := client.Call(context.Background(), "add", nil, 1, 2); !errors.Is(err, ErrRPCClosed) {
+		t.Errorf("Call() error = %v, want ErrRPCClosed", err)
+	}
+}
+
+func TestRPCClientDuplicateResponse(t *testing.T) {
+	// answers every request three times, the extra answers must not stall the client
+	worker := `import json, sys
+for line in sys.stdin:
+    msg = json.loads(line)
+    resp = json.dumps({"jsonrpc": "2.0", "id": msg["id"], "result": msg["params"][0]})
+    sys.stdout.write((resp + "\n") * 3)
+    sys.stdout.flush()
+`
+	client, err := NewRPCClient(context.Background(), Interop{Language: "python", Source: strings.NewReader(worker)})
+	if err != nil {
+		t.Fatalf("NewRPCClient() error = %v", err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for i := 0; i < 5; i++ {
+		var got int
+		if err := client.Call(ctx, "echo", &got, i); err != nil || got != i {
+			t.Fatalf("Call(echo, %d) = %d, %v", i, got, err)
+		}
+	}
+}
+
+func TestRPCClientInterpreterNotFound(t *testing.T) {
+	_, err := NewRPCClient(context.Background(), Interop{
+		Language:    "python",
+		FilePath:    getAbs(TEST_DATA_PATH, "rpc_worker.py"),
+		Interpreter: filepath.Join(t.TempDir(), "python3"),
+	})
+	if !errors.Is(err, ErrInterpreterNotFound) {
+		t.Errorf("NewRPCClient() error = %v, want %v", err, ErrInterpreterNotFound)
+	}
+}
+
+func ExampleRPCClient() {
+	client, err := NewRPCClient(context.Background(), Interop{
+		Language: "python",
+		FilePath: getAbs(TEST_DATA_PATH, "rpc_worker.py"),
+	})
+	if err != nil {
+		panic(err)
+	}
+	defer client.Close()
+
+	var sum int
+	if err := client.Call(context.Background(), "add", &sum, 40, 2); err != nil {
+		panic(err)
+	}
+	fmt.Println(sum)
+	// Output: 42
+}
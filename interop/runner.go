@@ -1,5 +1,27 @@
 package interop
 
+import (
+	"context"
+	"io"
+	"time"
+)
+
 type InteropRunner interface {
 	Run() (string, error)
+	// RunContext is like Run but kills the script, and every process it
+	// spawned, once ctx is done.
+	RunContext(ctx context.Context) (string, error)
+	// RunStream runs the script while streaming stdin into the process and
+	// the process output into stdout and stderr as it is produced.
+	// Any of stdin, stdout and stderr may be nil.
+	RunStream(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) (*Result, error)
+}
+
+// Result describes a finished script execution
+type Result struct {
+	ExitCode int
+	Duration time.Duration
+	// Stderr holds everything the process wrote to stderr, regardless of
+	// whether a stderr writer was passed to RunStream.
+	Stderr string
 }
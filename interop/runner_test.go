@@ -1,7 +1,17 @@
 package interop
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestRunner(t *testing.T) {
@@ -9,10 +19,11 @@ func TestRunner(t *testing.T) {
 		interop Interop
 	}
 	tests := []struct {
-		name    string
-		args    args
-		want    string
-		wantErr bool
+		name        string
+		args        args
+		interpreter string
+		want        string
+		wantErr     bool
 	}{
 		{
 			name: "Test#JavascriptRunner",
@@ -34,9 +45,64 @@ func TestRunner(t *testing.T) {
 			},
 			want: "Hello World from Python",
 		},
+		{
+			name: "Test#BashRunner",
+			args: args{
+				interop: Interop{
+					FilePath: getAbs(TEST_DATA_PATH, "example.sh"),
+				},
+			},
+			interpreter: "bash",
+			want:        "Hello World from Bash",
+		},
+		{
+			name: "Test#PerlRunner",
+			args: args{
+				interop: Interop{
+					FilePath: getAbs(TEST_DATA_PATH, "example.pl"),
+				},
+			},
+			interpreter: "perl",
+			want:        "Hello World from Perl",
+		},
+		{
+			name: "Test#RubyRunner",
+			args: args{
+				interop: Interop{
+					FilePath: getAbs(TEST_DATA_PATH, "example.rb"),
+				},
+			},
+			interpreter: "ruby",
+			want:        "Hello World from Ruby",
+		},
+		{
+			name: "Test#PHPRunner",
+			args: args{
+				interop: Interop{
+					FilePath: getAbs(TEST_DATA_PATH, "example.php"),
+				},
+			},
+			interpreter: "php",
+			want:        "Hello World from PHP",
+		},
+		{
+			name: "Test#LuaRunner",
+			args: args{
+				interop: Interop{
+					FilePath: getAbs(TEST_DATA_PATH, "example.lua"),
+				},
+			},
+			interpreter: "lua",
+			want:        "Hello World from Lua",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			if tt.interpreter != "" {
+				if _, err := exec.LookPath(tt.interpreter); err != nil {
+					t.Skipf("%s not found in PATH", tt.interpreter)
+				}
+			}
 			got, err := NewInteropRunner(tt.args.interop).Run()
 			t.Logf("Success Invoke from %s: %+v\n", tt.args.interop.Language, got)
 			if (err != nil) != tt.wantErr {
@@ -49,3 +115,204 @@ func TestRunner(t *testing.T) {
 		})
 	}
 }
+
+func TestRunnerStream(t *testing.T) {
+	tests := []struct {
+		name         string
+		interop      Interop
+		stdin        string
+		want         string
+		wantExitCode int
+		wantErr      bool
+	}{
+		{
+			name: "Test#PythonRunner stdin",
+			interop: Interop{
+				Language: "python",
+				FilePath: getAbs(TEST_DATA_PATH, "echo.py"),
+			},
+			stdin: "hello\nworld\n",
+			want:  "HELLO\nWORLD\n",
+		},
+		{
+			name: "Test#JavascriptRunner",
+			interop: Interop{
+				Language: "javascript",
+				FilePath: getAbs(TEST_DATA_PATH, "example.js"),
+			},
+			want: "Hello World from Javascript",
+		},
+		{
+			name: "Test#PythonRunner non-zero exit",
+			interop: Interop{
+				Language: "python",
+				FilePath: getAbs(TEST_DATA_PATH, "not_exist.py"),
+			},
+			wantExitCode: 2,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout strings.Builder
+			res, err := NewInteropRunner(tt.interop).RunStream(context.Background(), strings.NewReader(tt.stdin), &stdout, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Runner.RunStream() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if res.ExitCode != tt.wantExitCode {
+				t.Errorf("Runner.RunStream() exit code = %v, want %v", res.ExitCode, tt.wantExitCode)
+			}
+			var exitErr *ExitError
+			if tt.wantErr && (!errors.As(err, &exitErr) || exitErr.Stderr == "") {
+				t.Errorf("Runner.RunStream() error = %v, want *ExitError with stderr", err)
+			}
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("Runner.RunStream() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunnerStreamStdinNotDrained(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	// the script exits without reading stdin, which never reaches EOF
+	_, err = NewInteropRunner(Interop{
+		Language: "python",
+		FilePath: getAbs(TEST_DATA_PATH, "example.py"),
+	}).RunStream(context.Background(), r, io.Discard, nil)
+	if err != nil {
+		t.Fatalf("Runner.RunStream() error = %v", err)
+	}
+
+	// a leaked copy goroutine would still be reading r and steal this
+	if _, err := w.Write([]byte("after")); err != nil {
+		t.Fatal(err)
+	}
+	r.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, 16)
+	n, err := r.Read(buf)
+	if err != nil || string(buf[:n]) != "after" {
+		t.Errorf("Read() after RunStream = %q, %v, want %q", buf[:n], err, "after")
+	}
+}
+
+func TestRunnerTimeout(t *testing.T) {
+	pidFile := filepath.Join(t.TempDir(), "pids")
+	runner := NewInteropRunner(Interop{
+		Language: "python",
+		FilePath: getAbs(TEST_DATA_PATH, "sleep.py"),
+		Args:     []string{pidFile},
+		Timeout:  500 * time.Millisecond,
+	})
+
+	start := time.Now()
+	_, err := runner.Run()
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("Runner.Run() error = %v, want *TimeoutError", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Runner.Run() error = %v, want errors.Is context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Runner.Run() took %s after timeout", elapsed)
+	}
+
+	// the grandchild must be killed with the script, not only outlive it unnoticed
+	b, err := os.ReadFile(pidFile)
+	if err != nil {
+		t.Fatalf("script did not record its child: %v", err)
+	}
+	var childPid, pgid int
+	if _, err := fmt.Sscan(string(b), &childPid, &pgid); err != nil {
+		t.Fatalf("pid file %q: %v", b, err)
+	}
+	waitProcessGroupGone(t, pgid)
+}
+
+func TestRunnerCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(200*time.Millisecond, cancel)
+
+	_, err := NewInteropRunner(Interop{
+		Language: "python",
+		FilePath: getAbs(TEST_DATA_PATH, "sleep.py"),
+	}).RunContext(ctx)
+	if !errors.Is(err, ErrCanceled) {
+		t.Errorf("Runner.RunContext() error = %v, want ErrCanceled", err)
+	}
+}
+
+func TestJavaRunner(t *testing.T) {
+	if _, err := exec.LookPath("javac"); err != nil {
+		t.Skip("javac not found in PATH")
+	}
+
+	got, err := NewInteropRunner(Interop{
+		Language: "java",
+		FilePath: getAbs(TEST_DATA_PATH, "example.java"),
+	}).Run()
+	if err != nil {
+		t.Fatalf("JavaRunner.Run() error = %v", err)
+	}
+	if want := "Hello World from Java"; got != want {
+		t.Errorf("JavaRunner.Run() = %v, want %v", got, want)
+	}
+
+	_, err = NewInteropRunner(Interop{
+		Language: "java",
+		FilePath: getAbs(TEST_DATA_PATH, "broken.java"),
+	}).Run()
+	var compileErr *CompileError
+	if !errors.As(err, &compileErr) || compileErr.Diagnostics == "" {
+		t.Errorf("JavaRunner.Run() error = %v, want *CompileError with diagnostics", err)
+	}
+}
+
+func TestJavaRunnerCache(t *testing.T) {
+	java, err := exec.LookPath("java")
+	if err != nil {
+		t.Skip("java not found in PATH")
+	}
+	javac, err := exec.LookPath("javac")
+	if err != nil {
+		t.Skip("javac not found in PATH")
+	}
+	if runtime.GOOS == "windows" {
+		t.Skip("the javac wrapper is a shell script")
+	}
+
+	// javac is taken from the directory of java, the wrapper logs every compilation
+	dir := t.TempDir()
+	if err := os.Symlink(java, filepath.Join(dir, "java")); err != nil {
+		t.Fatal(err)
+	}
+	calls := filepath.Join(dir, "calls")
+	wrapper := fmt.Sprintf("#!/bin/sh\necho javac >> %q\nexec %q \"$@\"\n", calls, javac)
+	if err := os.WriteFile(filepath.Join(dir, "javac"), []byte(wrapper), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	for run := 1; run <= 2; run++ {
+		got, err := NewInteropRunner(Interop{
+			Language:    "java",
+			FilePath:    getAbs(TEST_DATA_PATH, "example.java"),
+			Interpreter: filepath.Join(dir, "java"),
+		}).Run()
+		if err != nil || got != "Hello World from Java" {
+			t.Fatalf("run %d: JavaRunner.Run() = %q, %v", run, got, err)
+		}
+	}
+
+	log, _ := os.ReadFile(calls)
+	if n := strings.Count(string(log), "javac"); n != 1 {
+		t.Errorf("javac ran %d times for the same source, want 1", n)
+	}
+}
@@ -0,0 +1,184 @@
+package interop
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Sandbox restricts the resources and environment of a script run.
+// Limits are best effort: CPU time and memory are applied with rlimits on
+// Linux, set by a /bin/sh wrapper before it execs the script, other
+// platforms reject them.
+type Sandbox struct {
+	// CPUTime caps the CPU time the process may consume, rounded up to whole seconds
+	CPUTime time.Duration
+	// Memory caps the data segment of the process in bytes (RLIMIT_DATA).
+	// A failed run is reported as exceeding it when the runtime reported an
+	// allocation failure on stderr after using most of the limit.
+	Memory int64
+	// MaxOutput caps the bytes written to stdout and stderr combined
+	MaxOutput int64
+	// AllowEnv lists the variables inherited from the parent environment,
+	// nothing is inherited by default
+	AllowEnv []string
+	// Env holds extra KEY=VALUE entries for the process environment
+	Env []string
+	// Dir is the working directory, a temporary directory removed after the run when empty
+	Dir string
+}
+
+// Resources a sandboxed run can exceed, see LimitError
+const (
+	LimitCPU    = "cpu"
+	LimitMemory = "memory"
+	LimitOutput = "output"
+)
+
+// sandboxRun holds the state of a single sandboxed execution
+type sandboxRun struct {
+	sandbox *Sandbox
+	tmpDir  string
+	output  *limitWriter
+}
+
+// prepare restricts cmd to the sandbox environment and working directory
+func (s *Sandbox) prepare(cmd *exec.Cmd, kill func()) (*sandboxRun, error) {
+	if err := checkLimitsSupported(s); err != nil {
+		return nil, err
+	}
+
+	if err := limitCommand(cmd, s); err != nil {
+		return nil, fmt.Errorf("failed to apply sandbox limits: %v", err)
+	}
+
+	run := &sandboxRun{sandbox: s}
+
+	cmd.Env = []string{}
+	for _, key := range s.AllowEnv {
+		if value, ok := os.LookupEnv(key); ok {
+			cmd.Env = append(cmd.Env, key+"="+value)
+		}
+	}
+	cmd.Env = append(cmd.Env, s.Env...)
+
+	cmd.Dir = s.Dir
+	if cmd.Dir == "" {
+		tmpDir, err := os.MkdirTemp("", "interop-sandbox-")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create sandbox dir: %v", err)
+		}
+		run.tmpDir = tmpDir
+		cmd.Dir = tmpDir
+	}
+
+	if s.MaxOutput > 0 {
+		run.output = &limitWriter{remaining: s.MaxOutput, exceeded: kill}
+		cmd.Stdout = run.output.wrap(cmd.Stdout)
+		cmd.Stderr = run.output.wrap(cmd.Stderr)
+	}
+
+	return run, nil
+}
+
+// exceeded returns the LimitError for a finished run, or nil when no limit was hit
+func (run *sandboxRun) exceeded(state *os.ProcessState, stderr string) error {
+	s := run.sandbox
+	if run.output != nil && run.output.isExceeded() {
+		return &LimitError{Resource: LimitOutput, Limit: fmt.Sprintf("%d bytes", s.MaxOutput)}
+	}
+	if s.CPUTime > 0 && cpuLimitExceeded(state, s.CPUTime) {
+		return &LimitError{Resource: LimitCPU, Limit: s.CPUTime.String()}
+	}
+	if s.Memory > 0 && memoryLimitExceeded(state, stderr, s.Memory) {
+		return &LimitError{Resource: LimitMemory, Limit: fmt.Sprintf("%d bytes", s.Memory)}
+	}
+	return nil
+}
+
+// outOfMemoryMarkers are what runtimes print when an allocation fails
+var outOfMemoryMarkers = []string{
+	"MemoryError",                // python
+	"heap out of memory",         // node
+	"java.lang.OutOfMemoryError", // java
+	"Cannot allocate memory",     // ENOMEM from libc
+	"failed to allocate memory",  // ruby
+	"Allowed memory size of",     // php
+	"std::bad_alloc",             // c++
+}
+
+func reportsOutOfMemory(stderr string) bool {
+	for _, marker := range outOfMemoryMarkers {
+		if strings.Contains(stderr, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+func (run *sandboxRun) cleanup() {
+	if run.tmpDir != "" {
+		os.RemoveAll(run.tmpDir)
+	}
+}
+
+// limitWriter counts the bytes written through all writers it wrapped and
+// calls exceeded once, when the budget is used up.
+type limitWriter struct {
+	mu        sync.Mutex
+	remaining int64
+	exceeded  func()
+	hit       bool
+}
+
+func (l *limitWriter) wrap(w io.Writer) io.Writer {
+	if w == nil {
+		w = io.Discard
+	}
+	return &limitedWriter{w: w, limit: l}
+}
+
+func (l *limitWriter) take(n int) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if int64(n) <= l.remaining {
+		l.remaining -= int64(n)
+		return n
+	}
+
+	allowed := int(l.remaining)
+	l.remaining = 0
+	if !l.hit {
+		l.hit = true
+		l.exceeded()
+	}
+	return allowed
+}
+
+func (l *limitWriter) isExceeded() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.hit
+}
+
+type limitedWriter struct {
+	w     io.Writer
+	limit *limitWriter
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	allowed := w.limit.take(len(p))
+	if allowed > 0 {
+		if _, err := w.w.Write(p[:allowed]); err != nil {
+			return 0, err
+		}
+	}
+	// report everything as written, the process is being killed anyway
+	return len(p), nil
+}
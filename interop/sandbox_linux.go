@@ -0,0 +1,75 @@
+//go:build linux
+
+package interop
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+)
+
+func checkLimitsSupported(s *Sandbox) error {
+	return nil
+}
+
+// limitCommand runs cmd through a /bin/sh wrapper that sets the rlimits and
+// then execs the script, so neither the script nor anything it forks ever
+// runs without them. The wrapper keeps the pid, cancellation is unaffected.
+func limitCommand(cmd *exec.Cmd, s *Sandbox) error {
+	var ulimits []string
+	if s.CPUTime > 0 {
+		secs := uint64(math.Ceil(s.CPUTime.Seconds()))
+		// SIGXCPU at the soft limit, SIGKILL one second later if the script ignores it.
+		// The soft limit goes first, it may never exceed the hard one.
+		ulimits = append(ulimits, fmt.Sprintf("ulimit -S -t %d", secs), fmt.Sprintf("ulimit -H -t %d", secs+1))
+	}
+	if s.Memory > 0 {
+		ulimits = append(ulimits, fmt.Sprintf("ulimit -d %d", max(s.Memory/1024, 1)))
+	}
+	if len(ulimits) == 0 || cmd.Err != nil {
+		// a failed lookup is reported by Start
+		return nil
+	}
+
+	const shell = "/bin/sh"
+	if _, err := os.Stat(shell); err != nil {
+		return err
+	}
+	script := strings.Join(ulimits, " && ") + ` && exec "$0" "$@"`
+	cmd.Args = append([]string{"sh", "-c", script, cmd.Path}, cmd.Args[1:]...)
+	cmd.Path = shell
+	return nil
+}
+
+func cpuLimitExceeded(state *os.ProcessState, limit time.Duration) bool {
+	status, ok := state.Sys().(syscall.WaitStatus)
+	if !ok || !status.Signaled() {
+		return false
+	}
+
+	switch status.Signal() {
+	case syscall.SIGXCPU:
+		return true
+	case syscall.SIGKILL:
+		return state.UserTime()+state.SystemTime() >= limit.Truncate(time.Second)
+	}
+	return false
+}
+
+// memoryLimitExceeded needs evidence of a failed allocation: the runtime
+// reported running out of memory on stderr while the process was close to
+// the limit. Either alone is an ordinary failure, a script may print the
+// text or use a lot of memory and then fail for its own reasons.
+func memoryLimitExceeded(state *os.ProcessState, stderr string, limit int64) bool {
+	if state.Success() || !reportsOutOfMemory(stderr) {
+		return false
+	}
+
+	usage, ok := state.SysUsage().(*syscall.Rusage)
+	// Maxrss is in kilobytes on linux, the allocation that failed is not in it
+	return ok && usage.Maxrss*1024 >= limit-limit/4
+}
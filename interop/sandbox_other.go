@@ -0,0 +1,29 @@
+//go:build !linux
+
+package interop
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"time"
+)
+
+func checkLimitsSupported(s *Sandbox) error {
+	if s.CPUTime > 0 || s.Memory > 0 {
+		return errors.New("sandbox CPU and memory limits are only supported on linux")
+	}
+	return nil
+}
+
+func limitCommand(cmd *exec.Cmd, s *Sandbox) error {
+	return nil
+}
+
+func cpuLimitExceeded(state *os.ProcessState, limit time.Duration) bool {
+	return false
+}
+
+func memoryLimitExceeded(state *os.ProcessState, stderr string, limit int64) bool {
+	return false
+}
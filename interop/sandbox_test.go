@@ -0,0 +1,166 @@
+package interop
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSandbox(t *testing.T) {
+	tests := []struct {
+		name         string
+		script       string
+		sandbox      Sandbox
+		linuxOnly    bool
+		wantResource string
+	}{
+		{
+			name:         "Test#CPUTime",
+			script:       "cpu.py",
+			sandbox:      Sandbox{CPUTime: time.Second},
+			linuxOnly:    true,
+			wantResource: LimitCPU,
+		},
+		{
+			name:         "Test#Memory",
+			script:       "memory.py",
+			sandbox:      Sandbox{Memory: 256 << 20},
+			linuxOnly:    true,
+			wantResource: LimitMemory,
+		},
+		{
+			name:         "Test#MaxOutput",
+			script:       "flood.py",
+			sandbox:      Sandbox{MaxOutput: 1024},
+			wantResource: LimitOutput,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.linuxOnly && runtime.GOOS != "linux" {
+				t.Skip("rlimits are only applied on linux")
+			}
+
+			_, err := NewInteropRunner(Interop{
+				Language: "python",
+				FilePath: getAbs(TEST_DATA_PATH, "sandbox", tt.script),
+				Timeout:  30 * time.Second,
+				Sandbox:  &tt.sandbox,
+			}).Run()
+
+			var limitErr *LimitError
+			if !errors.As(err, &limitErr) || limitErr.Resource != tt.wantResource {
+				t.Fatalf("Runner.Run() error = %v, want %s LimitError", err, tt.wantResource)
+			}
+			if !errors.Is(err, ErrLimitExceeded) {
+				t.Errorf("Runner.Run() error = %v, want errors.Is ErrLimitExceeded", err)
+			}
+		})
+	}
+}
+
+func TestSandboxLimitsBeforeExec(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("rlimits are only applied on linux")
+	}
+
+	got, err := NewInteropRunner(Interop{
+		Language: "python",
+		FilePath: getAbs(TEST_DATA_PATH, "sandbox", "limits.py"),
+		Sandbox:  &Sandbox{CPUTime: 2 * time.Second, Memory: 512 << 20},
+	}).Run()
+	if err != nil {
+		t.Fatalf("Runner.Run() error = %v", err)
+	}
+	if want := fmt.Sprintf("2 %d", 512<<20); got != want {
+		t.Errorf("rlimits at script start = %q, want %q", got, want)
+	}
+}
+
+func TestSandboxMemoryNotExceeded(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("rlimits are only applied on linux")
+	}
+
+	// each is an ordinary failure, not evidence of a failed allocation
+	tests := []struct {
+		name     string
+		script   string
+		exitCode int
+	}{
+		{name: "Test#OutOfMemoryText", script: "fake_oom.py", exitCode: 1},
+		{name: "Test#Abort", script: "abort.py", exitCode: -1},
+		{name: "Test#AllocateThenExit", script: "alloc_exit.py", exitCode: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewInteropRunner(Interop{
+				Language: "python",
+				FilePath: getAbs(TEST_DATA_PATH, "sandbox", tt.script),
+				Sandbox:  &Sandbox{Memory: 256 << 20},
+			}).Run()
+			var exitErr *ExitError
+			if errors.Is(err, ErrLimitExceeded) || !errors.As(err, &exitErr) {
+				t.Fatalf("Runner.Run() error = %v, want *ExitError", err)
+			}
+			if exitErr.ExitCode != tt.exitCode {
+				t.Errorf("ExitError.ExitCode = %d, want %d", exitErr.ExitCode, tt.exitCode)
+			}
+			if exitErr.Stderr == "" && strings.Contains(err.Error(), "stderr:") {
+				t.Errorf("Runner.Run() error = %q, want no stderr section without stderr", err)
+			}
+		})
+	}
+}
+
+func TestSandboxEnv(t *testing.T) {
+	t.Setenv("HELPME_ALLOWED", "1")
+	t.Setenv("HELPME_SECRET", "1")
+
+	got, err := NewInteropRunner(Interop{
+		Language: "python",
+		FilePath: getAbs(TEST_DATA_PATH, "sandbox", "env.py"),
+		Sandbox: &Sandbox{
+			AllowEnv: []string{"HELPME_ALLOWED"},
+			Env:      []string{"HELPME_EXTRA=1"},
+		},
+	}).Run()
+	if err != nil {
+		t.Fatalf("Runner.Run() error = %v", err)
+	}
+
+	env := strings.Split(got, ",")
+	for _, want := range []string{"HELPME_ALLOWED", "HELPME_EXTRA"} {
+		if !slices.Contains(env, want) {
+			t.Errorf("sandbox env %v is missing %s", env, want)
+		}
+	}
+	for _, unwanted := range []string{"HELPME_SECRET", "HOME"} {
+		if slices.Contains(env, unwanted) {
+			t.Errorf("sandbox env %v must not contain %s", env, unwanted)
+		}
+	}
+}
+
+func TestSandboxDir(t *testing.T) {
+	got, err := NewInteropRunner(Interop{
+		Language: "python",
+		FilePath: getAbs(TEST_DATA_PATH, "sandbox", "cwd.py"),
+		Sandbox:  &Sandbox{},
+	}).Run()
+	if err != nil {
+		t.Fatalf("Runner.Run() error = %v", err)
+	}
+
+	if !strings.Contains(got, "interop-sandbox-") {
+		t.Errorf("sandbox working dir = %v, want a temp dir", got)
+	}
+	if _, err := os.Stat(got); !os.IsNotExist(err) {
+		t.Errorf("sandbox working dir %v was not removed", got)
+	}
+}
@@ -0,0 +1,49 @@
+package interop
+
+import (
+	"context"
+	"io"
+)
+
+// ScriptRunner runs a script through an interpreter that accepts the script
+// path followed by the script arguments, e.g. ruby, bash or perl.
+type ScriptRunner struct {
+	I           Interop
+	Interpreter string
+	// Flags are passed to the interpreter before the script path
+	Flags []string
+}
+
+func (runner *ScriptRunner) Run() (string, error) {
+	return runner.RunContext(context.Background())
+}
+
+func (runner *ScriptRunner) RunContext(ctx context.Context) (string, error) {
+	return runBuffered(ctx, runner.RunStream)
+}
+
+func (runner *ScriptRunner) resolveInterpreter() (string, error) {
+	return ResolveInterpreter(runner.I, runner.Interpreter)
+}
+
+func (runner *ScriptRunner) RunStream(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) (*Result, error) {
+	return runScript(ctx, runner.I, runner.Interpreter, runner.Flags, stdin, stdout, stderr)
+}
+
+// runScript runs "interpreter flags... script args..." for the script of i
+func runScript(ctx context.Context, i Interop, interpreter string, flags []string, stdin io.Reader, stdout, stderr io.Writer) (*Result, error) {
+	bin, err := ResolveInterpreter(i, interpreter)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := interpreterSpecs[interpreter]
+	script, stdin, cleanup, err := prepareSource(i, spec.stdinArg, spec.ext, stdin)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	args := append(append(append([]string{}, flags...), script), i.Args...)
+	return runCommand(ctx, i, bin, args, stdin, stdout, stderr)
+}
@@ -0,0 +1,152 @@
+package interop
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// prepareSource returns the script argument for the interpreter, the stdin to
+// run it with and a cleanup func that must be called once the run finished.
+//
+// Inline Interop.Source is piped through stdin using stdinArg when the caller
+// has no stdin of its own, otherwise it is written to a temp file named after
+// FilePath, or "script"+ext when FilePath is empty.
+func prepareSource(i Interop, stdinArg string, ext string, stdin io.Reader) (string, io.Reader, func(), error) {
+	noop := func() {}
+
+	if i.Source == nil {
+		// Get absolute path
+		absPath, err := filepath.Abs(i.FilePath)
+		if err != nil {
+			return "", nil, noop, fmt.Errorf("failed to get absolute path: %v", err)
+		}
+		return absPath, stdin, noop, nil
+	}
+
+	src, err := readSource(i)
+	if err != nil {
+		return "", nil, noop, err
+	}
+
+	if stdinArg != "" && stdin == nil {
+		return stdinArg, bytes.NewReader(src), noop, nil
+	}
+
+	name := "script" + ext
+	if i.FilePath != "" {
+		name = filepath.Base(i.FilePath)
+	}
+
+	path, cleanup, err := writeSource(bytes.NewReader(src), name)
+	if err != nil {
+		return "", nil, noop, err
+	}
+	return path, stdin, cleanup, nil
+}
+
+// writeSource writes src to name inside a fresh temp dir, cleanup removes the dir
+func writeSource(src io.Reader, name string) (string, func(), error) {
+	tmpDir, err := os.MkdirTemp("", "interop-source-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temp dir: %v", err)
+	}
+	cleanup := func() { os.RemoveAll(tmpDir) }
+
+	path := filepath.Join(tmpDir, name)
+	f, err := os.Create(path)
+	if err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to create source file: %v", err)
+	}
+
+	if _, err := io.Copy(f, src); err != nil {
+		f.Close()
+		cleanup()
+		return "", nil, fmt.Errorf("failed to write source file: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to write source file: %v", err)
+	}
+
+	return path, cleanup, nil
+}
+
+// readSource buffers the inline source of i so it can be replayed, e.g. for every pool worker
+func readSource(i Interop) ([]byte, error) {
+	if i.Source == nil {
+		return nil, nil
+	}
+
+	if shared, ok := i.Source.(*sharedSource); ok {
+		return shared.bytes()
+	}
+
+	src, err := io.ReadAll(i.Source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source: %v", err)
+	}
+	return src, nil
+}
+
+// sharedSource reads the inline source of a runner once, on its first run,
+// and hands the same bytes to every later run.
+type sharedSource struct {
+	once sync.Once
+	src  io.Reader
+	data []byte
+	err  error
+	// r serves Read for callers outside the package
+	r *bytes.Reader
+}
+
+func newSharedSource(src io.Reader) io.Reader {
+	if shared, ok := src.(*sharedSource); ok {
+		return shared
+	}
+	return &sharedSource{src: src}
+}
+
+func (s *sharedSource) bytes() ([]byte, error) {
+	s.once.Do(func() {
+		s.data, s.err = io.ReadAll(s.src)
+		if s.err != nil {
+			s.err = fmt.Errorf("failed to read source: %v", s.err)
+		}
+		s.r = bytes.NewReader(s.data)
+	})
+	return s.data, s.err
+}
+
+func (s *sharedSource) Read(p []byte) (int, error) {
+	if _, err := s.bytes(); err != nil {
+		return 0, err
+	}
+	return s.r.Read(p)
+}
+
+var javaClassPattern = regexp.MustCompile(`(?m)^[ \t]*((?:\w+[ \t]+)*)class[ \t]+([A-Za-z_$][\w$]*)`)
+
+// javaClassName finds the class to run in inline java source: the public
+// class if there is one, the first declared class otherwise.
+func javaClassName(src []byte) (string, bool) {
+	matches := javaClassPattern.FindAllSubmatch(src, -1)
+	if len(matches) == 0 {
+		return "", false
+	}
+
+	for _, m := range matches {
+		for _, modifier := range strings.Fields(string(m[1])) {
+			if modifier == "public" {
+				return string(m[2]), true
+			}
+		}
+	}
+	return string(matches[0][2]), true
+}
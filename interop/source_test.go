@@ -0,0 +1,130 @@
+package interop
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunnerSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		interop Interop
+		stdin   io.Reader
+		want    string
+	}{
+		{
+			name: "Test#PythonRunner via stdin",
+			interop: Interop{
+				Language: "python",
+				Source:   strings.NewReader(`import sys; print("Hello " + sys.argv[1], end="")`),
+				Args:     []string{"Python"},
+			},
+			want: "Hello Python",
+		},
+		{
+			name: "Test#JavascriptRunner via stdin",
+			interop: Interop{
+				Language: "javascript",
+				Source:   strings.NewReader(`process.stdout.write("Hello " + process.argv[2])`),
+				Args:     []string{"Javascript"},
+			},
+			want: "Hello Javascript",
+		},
+		{
+			name: "Test#PythonRunner via temp file",
+			interop: Interop{
+				Language: "python",
+				Source:   strings.NewReader(`import sys; print(sys.stdin.read().upper(), end="")`),
+			},
+			stdin: strings.NewReader("hello"),
+			want:  "HELLO",
+		},
+		{
+			name: "Test#BashRunner detected from FilePath",
+			interop: Interop{
+				FilePath: "snippet.sh",
+				Source:   strings.NewReader(`printf "Hello %s" "$1"`),
+				Args:     []string{"Bash"},
+			},
+			want: "Hello Bash",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout strings.Builder
+			_, err := NewInteropRunner(tt.interop).RunStream(context.Background(), tt.stdin, &stdout, nil)
+			if err != nil {
+				t.Fatalf("Runner.RunStream() error = %v", err)
+			}
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("Runner.RunStream() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunnerSourceRunTwice(t *testing.T) {
+	runner := NewInteropRunner(Interop{
+		Language: "python",
+		Source:   strings.NewReader(`print("again", end="")`),
+	})
+	for run := 1; run <= 2; run++ {
+		got, err := runner.Run()
+		if err != nil {
+			t.Fatalf("run %d: Runner.Run() error = %v", run, err)
+		}
+		if got != "again" {
+			t.Errorf("run %d: Runner.Run() = %q, want %q", run, got, "again")
+		}
+	}
+}
+
+func TestRunnerSourceCleanup(t *testing.T) {
+	stdoutR, stdoutW := io.Pipe()
+	pathCh := make(chan string, 1)
+	go func() {
+		line, _ := bufio.NewReader(stdoutR).ReadString('\n')
+		pathCh <- strings.TrimSpace(line)
+		io.Copy(io.Discard, stdoutR)
+	}()
+
+	_, err := NewInteropRunner(Interop{
+		Language: "python",
+		Source:   strings.NewReader("import sys, time\nprint(sys.argv[0], flush=True)\ntime.sleep(30)\n"),
+		Timeout:  500 * time.Millisecond,
+	}).RunStream(context.Background(), strings.NewReader(""), stdoutW, nil)
+	stdoutW.Close()
+
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("Runner.RunStream() error = %v, want *TimeoutError", err)
+	}
+
+	path := <-pathCh
+	if !strings.Contains(path, "interop-source-") {
+		t.Fatalf("script path = %q, want a temp file", path)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("temp source %s was not removed", path)
+	}
+}
+
+func TestJavaClassName(t *testing.T) {
+	tests := map[string]string{
+		"class Main {}":                             "Main",
+		"class Helper {}\npublic class App {}":      "App",
+		"final class Util<T> {}\nclass Other {}":    "Util",
+		"  public final class Greeter implements X": "Greeter",
+	}
+	for src, want := range tests {
+		if got, ok := javaClassName([]byte(src)); !ok || got != want {
+			t.Errorf("javaClassName(%q) = %q, want %q", src, got, want)
+		}
+	}
+}
@@ -1,7 +1,30 @@
 package interop
 
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// UnknownRunner is returned by NewInteropRunner for languages that are not registered,
+// every run fails with ErrUnsupportedLanguage.
 type UnknownRunner struct{ I Interop }
 
 func (runner *UnknownRunner) Run() (string, error) {
-	return "", nil
+	return runner.RunContext(context.Background())
+}
+
+func (runner *UnknownRunner) RunContext(ctx context.Context) (string, error) {
+	return "", runner.err()
+}
+
+func (runner *UnknownRunner) RunStream(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) (*Result, error) {
+	return nil, runner.err()
+}
+
+func (runner *UnknownRunner) err() error {
+	if runner.I.Language == "" {
+		return fmt.Errorf("%w: cannot detect language of %q", ErrUnsupportedLanguage, runner.I.FilePath)
+	}
+	return fmt.Errorf("%w: %q", ErrUnsupportedLanguage, runner.I.Language)
 }
@@ -0,0 +1,155 @@
+package interop
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	dottedVersionPattern = regexp.MustCompile(`\d+(\.\d+)+`)
+	versionPattern       = regexp.MustCompile(`\d+(\.\d+)*`)
+)
+
+// version is a dotted numeric version such as 3.11.4
+type version []int
+
+func parseVersion(s string) (version, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if s == "" {
+		return nil, fmt.Errorf("empty version")
+	}
+
+	parts := strings.Split(s, ".")
+	v := make(version, 0, len(parts))
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version %q", s)
+		}
+		v = append(v, n)
+	}
+	return v, nil
+}
+
+// findVersion extracts the first dotted version from the output of e.g.
+// "python3 --version", or the first number for dotless versions such as
+// "openjdk 21 2023-09-19".
+func findVersion(output string) (version, error) {
+	// "perl 5, version 34, ... (v5.34.0)" names its full version last
+	match := dottedVersionPattern.FindString(output)
+	if match == "" {
+		match = versionPattern.FindString(output)
+	}
+	if match == "" {
+		return nil, fmt.Errorf("no version found in %q", strings.TrimSpace(output))
+	}
+	return parseVersion(match)
+}
+
+// compare returns -1, 0 or 1, missing components count as zero
+func (v version) compare(o version) int {
+	for i := 0; i < len(v) || i < len(o); i++ {
+		var a, b int
+		if i < len(v) {
+			a = v[i]
+		}
+		if i < len(o) {
+			b = o[i]
+		}
+		if a != b {
+			if a < b {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// hasPrefix reports whether every component of p matches v, so 3.10 matches 3.10.4
+func (v version) hasPrefix(p version) bool {
+	if len(p) > len(v) {
+		return false
+	}
+	for i := range p {
+		if v[i] != p[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (v version) String() string {
+	parts := make([]string, len(v))
+	for i, n := range v {
+		parts[i] = strconv.Itoa(n)
+	}
+	return strings.Join(parts, ".")
+}
+
+// versionConstraint is a comma separated list of comparisons, e.g. ">=3.8, <4".
+// A bare version is a minimum, "=3.10" matches any 3.10.x release.
+type versionConstraint []versionComparison
+
+type versionComparison struct {
+	op string
+	v  version
+}
+
+func parseVersionConstraint(s string) (versionConstraint, error) {
+	var c versionConstraint
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		op := ">="
+		for _, candidate := range []string{">=", "<=", "==", "!=", ">", "<", "="} {
+			if strings.HasPrefix(part, candidate) {
+				op, part = candidate, part[len(candidate):]
+				break
+			}
+		}
+		if op == "==" {
+			op = "="
+		}
+
+		v, err := parseVersion(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version constraint %q: %v", s, err)
+		}
+		c = append(c, versionComparison{op: op, v: v})
+	}
+
+	if len(c) == 0 {
+		return nil, fmt.Errorf("invalid version constraint %q", s)
+	}
+	return c, nil
+}
+
+func (c versionConstraint) check(v version) bool {
+	for _, cmp := range c {
+		var ok bool
+		switch cmp.op {
+		case ">=":
+			ok = v.compare(cmp.v) >= 0
+		case ">":
+			ok = v.compare(cmp.v) > 0
+		case "<=":
+			ok = v.compare(cmp.v) <= 0
+		case "<":
+			ok = v.compare(cmp.v) < 0
+		case "=":
+			ok = v.hasPrefix(cmp.v)
+		case "!=":
+			ok = !v.hasPrefix(cmp.v)
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
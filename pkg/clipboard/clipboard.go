@@ -0,0 +1,72 @@
+package clipboard
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+)
+
+// Format is the kind of data on the clipboard
+type Format int
+
+const (
+	// FormatText is UTF-8 text
+	FormatText Format = iota
+	// FormatImage is a PNG encoded image
+	FormatImage
+)
+
+func (f Format) String() string {
+	switch f {
+	case FormatText:
+		return "text"
+	case FormatImage:
+		return "image"
+	default:
+		return "unknown"
+	}
+}
+
+var (
+	// ErrUnavailable is returned when no clipboard can be reached, e.g. without a display
+	ErrUnavailable = errors.New("clipboard: unavailable")
+	// ErrUnsupported is returned for operations or formats a backend cannot handle
+	ErrUnsupported = errors.New("clipboard: unsupported")
+)
+
+// Clipboard reads, writes and watches the system clipboard or a stand-in for it
+type Clipboard interface {
+	// Read returns the current content in format, nil when there is none
+	Read(ctx context.Context, format Format) ([]byte, error)
+	// Write replaces the content in format, it returns once the data is on the clipboard
+	Write(ctx context.Context, format Format, data []byte) error
+	// Watch sends the content in format every time it changes until ctx is done,
+	// the channel is closed afterwards.
+	Watch(ctx context.Context, format Format) (<-chan []byte, error)
+}
+
+// Detect picks a backend for the current environment: OSC52 escapes over SSH
+// without a display, the desktop clipboard tools, then the native library.
+// The escapes go to the terminal, never to stdout, which may be piped.
+func Detect() (Clipboard, error) {
+	display := os.Getenv("DISPLAY") != "" || os.Getenv("WAYLAND_DISPLAY") != ""
+	if !display && (os.Getenv("SSH_TTY") != "" || os.Getenv("SSH_CONNECTION") != "") {
+		return NewOSC52(terminal()), nil
+	}
+
+	if cb, err := NewExec(); err == nil {
+		return cb, nil
+	}
+
+	return NewNative()
+}
+
+// terminal is the controlling terminal, or stderr without one. The tty stays
+// open, the clipboard may be written to until the program exits.
+func terminal() io.Writer {
+	if tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0); err == nil {
+		return tty
+	}
+	return os.Stderr
+}
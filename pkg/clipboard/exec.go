@@ -0,0 +1,189 @@
+package clipboard
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// defaultPollInterval is how often Exec reads the clipboard to watch it
+const defaultPollInterval = 500 * time.Millisecond
+
+// execTool is a command line clipboard program, nil arguments mark an
+// unsupported format.
+type execTool struct {
+	name       string
+	copyText   []string
+	copyImage  []string
+	pasteText  []string
+	pasteImage []string
+}
+
+var execTools = []execTool{
+	{
+		name:       "wl-copy",
+		copyText:   []string{"wl-copy"},
+		copyImage:  []string{"wl-copy", "--type", "image/png"},
+		pasteText:  []string{"wl-paste", "--no-newline"},
+		pasteImage: []string{"wl-paste", "--no-newline", "--type", "image/png"},
+	},
+	{
+		name:       "xclip",
+		copyText:   []string{"xclip", "-selection", "clipboard"},
+		copyImage:  []string{"xclip", "-selection", "clipboard", "-t", "image/png"},
+		pasteText:  []string{"xclip", "-selection", "clipboard", "-o"},
+		pasteImage: []string{"xclip", "-selection", "clipboard", "-o", "-t", "image/png"},
+	},
+	{
+		name:      "xsel",
+		copyText:  []string{"xsel", "--clipboard", "--input"},
+		pasteText: []string{"xsel", "--clipboard", "--output"},
+	},
+	{
+		name:      "pbcopy",
+		copyText:  []string{"pbcopy"},
+		pasteText: []string{"pbpaste"},
+	},
+}
+
+// Exec drives a clipboard program such as wl-copy, xclip, xsel or pbcopy
+type Exec struct {
+	tool execTool
+	// PollInterval is how often Watch reads the clipboard
+	PollInterval time.Duration
+}
+
+// NewExec uses the first of the given tools found in PATH, by default
+// wl-copy under Wayland, then xclip, xsel and pbcopy.
+func NewExec(tools ...string) (*Exec, error) {
+	if len(tools) == 0 {
+		for _, t := range execTools {
+			tools = append(tools, t.name)
+		}
+		if os.Getenv("WAYLAND_DISPLAY") == "" {
+			// wl-copy cannot reach an X11 clipboard
+			tools = tools[1:]
+		}
+	}
+
+	for _, name := range tools {
+		for _, t := range execTools {
+			if t.name != name {
+				continue
+			}
+			if _, err := exec.LookPath(t.copyText[0]); err != nil {
+				continue
+			}
+			if _, err := exec.LookPath(t.pasteText[0]); err != nil {
+				continue
+			}
+			return &Exec{tool: t, PollInterval: defaultPollInterval}, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: none of %s found", ErrUnavailable, strings.Join(tools, ", "))
+}
+
+func (e *Exec) Read(ctx context.Context, format Format) ([]byte, error) {
+	args, err := e.args(format, e.tool.pasteText, e.tool.pasteImage)
+	if err != nil {
+		return nil, err
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out, nil
+}
+
+func (e *Exec) Write(ctx context.Context, format Format, data []byte) error {
+	args, err := e.args(format, e.tool.copyText, e.tool.copyImage)
+	if err != nil {
+		return err
+	}
+
+	// wl-copy and xclip fork a process serving the clipboard that inherits
+	// stderr, Run would wait on a pipe until it exits but not on a file
+	stderr, err := os.CreateTemp("", "clipboard-stderr-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(stderr.Name())
+	defer stderr.Close()
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		msg, _ := os.ReadFile(stderr.Name())
+		return fmt.Errorf("%s: %w: %s", args[0], err, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// Watch polls the clipboard every PollInterval, the content at the time of
+// the call is not sent.
+func (e *Exec) Watch(ctx context.Context, format Format) (<-chan []byte, error) {
+	last, err := e.Read(ctx, format)
+	if err != nil {
+		return nil, err
+	}
+
+	interval := e.PollInterval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+
+	ch := make(chan []byte)
+	go func() {
+		defer close(ch)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			// a failed read, e.g. while another program owns the clipboard, is retried
+			data, err := e.Read(ctx, format)
+			if err != nil || bytes.Equal(data, last) {
+				continue
+			}
+			last = data
+
+			select {
+			case ch <- data:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+func (e *Exec) args(format Format, text, image []string) ([]string, error) {
+	var args []string
+	switch format {
+	case FormatText:
+		args = text
+	case FormatImage:
+		args = image
+	}
+	if args == nil {
+		return nil, fmt.Errorf("%w: %s cannot handle %s", ErrUnsupported, e.tool.name, format)
+	}
+	return args, nil
+}
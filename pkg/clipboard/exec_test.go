@@ -0,0 +1,90 @@
+package clipboard
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fakeXclip installs an xclip script keeping the clipboard in files below dir,
+// like xclip a copy leaves a process behind holding stderr.
+func fakeXclip(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	script := `#!/bin/sh
+store="$(dirname "$0")/text"
+out=0
+for a in "$@"; do
+	case "$a" in
+	-o) out=1 ;;
+	image/png) store="$(dirname "$0")/image" ;;
+	esac
+done
+if [ $out = 1 ]; then
+	cat "$store" 2>/dev/null || true
+else
+	cat > "$store"
+	sleep 5 >/dev/null &
+fi
+`
+	if err := os.WriteFile(filepath.Join(dir, "xclip"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestExec(t *testing.T) {
+	fakeXclip(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cb, err := NewExec("xclip")
+	if err != nil {
+		t.Fatalf("NewExec() error = %v", err)
+	}
+	cb.PollInterval = 10 * time.Millisecond
+
+	if got, err := cb.Read(ctx, FormatText); got != nil || err != nil {
+		t.Fatalf("Read() on an empty clipboard = %q, %v", got, err)
+	}
+
+	changes, err := cb.Watch(ctx, FormatText)
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+
+	start := time.Now()
+	if err := cb.Write(ctx, FormatText, []byte("copied")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Write() took %v, it waited on the process left behind", elapsed)
+	}
+	if err := cb.Write(ctx, FormatImage, []byte("\x89PNG")); err != nil {
+		t.Fatalf("Write(FormatImage) error = %v", err)
+	}
+
+	select {
+	case got := <-changes:
+		if string(got) != "copied" {
+			t.Errorf("Watch() sent %q, want %q", got, "copied")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Watch() did not see the change")
+	}
+
+	if got, _ := cb.Read(ctx, FormatImage); string(got) != "\x89PNG" {
+		t.Errorf("Read(FormatImage) = %q", got)
+	}
+}
+
+func TestExecUnavailable(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	if _, err := NewExec(); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("NewExec() error = %v, want %v", err, ErrUnavailable)
+	}
+}
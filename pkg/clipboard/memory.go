@@ -0,0 +1,68 @@
+package clipboard
+
+import (
+	"bytes"
+	"context"
+	"sync"
+)
+
+// watchBuffer is how many changes a watcher may lag behind before they are dropped
+const watchBuffer = 64
+
+// Memory is an in-process clipboard for tests and headless environments
+type Memory struct {
+	mu       sync.Mutex
+	data     map[Format][]byte
+	watchers map[chan []byte]Format
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		data:     map[Format][]byte{},
+		watchers: map[chan []byte]Format{},
+	}
+}
+
+func (m *Memory) Read(ctx context.Context, format Format) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return bytes.Clone(m.data[format]), nil
+}
+
+// Write stores data and notifies the watchers of format, a watcher that
+// fell watchBuffer changes behind misses the change.
+func (m *Memory) Write(ctx context.Context, format Format, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.data[format] = bytes.Clone(data)
+	for ch, f := range m.watchers {
+		if f != format {
+			continue
+		}
+		select {
+		case ch <- bytes.Clone(data):
+		default:
+		}
+	}
+	return nil
+}
+
+func (m *Memory) Watch(ctx context.Context, format Format) (<-chan []byte, error) {
+	ch := make(chan []byte, watchBuffer)
+
+	m.mu.Lock()
+	m.watchers[ch] = format
+	m.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		m.mu.Lock()
+		delete(m.watchers, ch)
+		close(ch)
+		m.mu.Unlock()
+	}()
+	return ch, nil
+}
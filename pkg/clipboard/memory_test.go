@@ -0,0 +1,51 @@
+package clipboard
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemory(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cb := NewMemory()
+	if got, err := cb.Read(ctx, FormatText); got != nil || err != nil {
+		t.Fatalf("Read() on an empty clipboard = %q, %v", got, err)
+	}
+
+	changes, err := cb.Watch(ctx, FormatText)
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+
+	cb.Write(ctx, FormatImage, []byte("\x89PNG"))
+	cb.Write(ctx, FormatText, []byte("first"))
+	cb.Write(ctx, FormatText, []byte("second"))
+
+	for _, want := range []string{"first", "second"} {
+		select {
+		case got := <-changes:
+			if string(got) != want {
+				t.Errorf("Watch() sent %q, want %q", got, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Watch() did not send %q", want)
+		}
+	}
+
+	if got, _ := cb.Read(ctx, FormatImage); string(got) != "\x89PNG" {
+		t.Errorf("Read(FormatImage) = %q", got)
+	}
+
+	cancel()
+	select {
+	case _, ok := <-changes:
+		if ok {
+			t.Errorf("Watch() sent after cancel")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Watch() channel not closed after cancel")
+	}
+}
@@ -0,0 +1,66 @@
+package clipboard
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	native "golang.design/x/clipboard"
+)
+
+var (
+	nativeOnce sync.Once
+	nativeErr  error
+)
+
+// Native uses the platform clipboard through golang.design/x/clipboard,
+// it needs a display and cgo on Linux.
+type Native struct{}
+
+func NewNative() (*Native, error) {
+	nativeOnce.Do(func() {
+		nativeErr = native.Init()
+	})
+	if nativeErr != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, nativeErr)
+	}
+	return &Native{}, nil
+}
+
+func (n *Native) Read(ctx context.Context, format Format) ([]byte, error) {
+	f, err := nativeFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	return native.Read(f), nil
+}
+
+// Write does not wait for the content to be replaced by another program,
+// the channel the library returns for that is dropped.
+func (n *Native) Write(ctx context.Context, format Format, data []byte) error {
+	f, err := nativeFormat(format)
+	if err != nil {
+		return err
+	}
+	native.Write(f, data)
+	return nil
+}
+
+func (n *Native) Watch(ctx context.Context, format Format) (<-chan []byte, error) {
+	f, err := nativeFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	return native.Watch(ctx, f), nil
+}
+
+func nativeFormat(format Format) (native.Format, error) {
+	switch format {
+	case FormatText:
+		return native.FmtText, nil
+	case FormatImage:
+		return native.FmtImage, nil
+	default:
+		return 0, fmt.Errorf("%w: format %s", ErrUnsupported, format)
+	}
+}
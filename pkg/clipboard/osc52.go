@@ -0,0 +1,50 @@
+package clipboard
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// OSC52 writes to the clipboard of the terminal emulator through the OSC 52
+// escape sequence, which also works over SSH. Terminals do not report the
+// clipboard back reliably, so Read and Watch are unsupported.
+type OSC52 struct {
+	mu sync.Mutex
+	w  io.Writer
+	// Tmux wraps the sequence in a tmux passthrough, set when $TMUX is
+	Tmux bool
+}
+
+// NewOSC52 writes escape sequences to w, usually the tty
+func NewOSC52(w io.Writer) *OSC52 {
+	return &OSC52{w: w, Tmux: os.Getenv("TMUX") != ""}
+}
+
+func (o *OSC52) Read(ctx context.Context, format Format) ([]byte, error) {
+	return nil, fmt.Errorf("%w: OSC52 cannot read the clipboard", ErrUnsupported)
+}
+
+func (o *OSC52) Write(ctx context.Context, format Format, data []byte) error {
+	if format != FormatText {
+		return fmt.Errorf("%w: OSC52 only carries text", ErrUnsupported)
+	}
+
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString(data) + "\a"
+	if o.Tmux {
+		seq = "\x1bPtmux;\x1b" + seq + "\x1b\\"
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	_, err := io.WriteString(o.w, seq)
+	return err
+}
+
+func (o *OSC52) Watch(ctx context.Context, format Format) (<-chan []byte, error) {
+	return nil, fmt.Errorf("%w: OSC52 cannot watch the clipboard", ErrUnsupported)
+}
@@ -0,0 +1,60 @@
+package clipboard
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestOSC52(t *testing.T) {
+	tests := []struct {
+		name string
+		tmux bool
+		want string
+	}{
+		{name: "plain", want: "\x1b]52;c;aGVsbG8=\a"},
+		{name: "tmux", tmux: true, want: "\x1bPtmux;\x1b\x1b]52;c;aGVsbG8=\a\x1b\\"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			cb := NewOSC52(&buf)
+			cb.Tmux = tt.tmux
+
+			if err := cb.Write(context.Background(), FormatText, []byte("hello")); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("Write() wrote %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+
+	cb := NewOSC52(&bytes.Buffer{})
+	if err := cb.Write(context.Background(), FormatImage, []byte("\x89PNG")); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Write(FormatImage) error = %v, want %v", err, ErrUnsupported)
+	}
+	if _, err := cb.Read(context.Background(), FormatText); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Read() error = %v, want %v", err, ErrUnsupported)
+	}
+}
+
+func TestDetectOSC52(t *testing.T) {
+	t.Setenv("DISPLAY", "")
+	t.Setenv("WAYLAND_DISPLAY", "")
+	t.Setenv("SSH_TTY", "/dev/pts/0")
+
+	cb, err := Detect()
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	osc, ok := cb.(*OSC52)
+	if !ok {
+		t.Fatalf("Detect() = %T, want *OSC52", cb)
+	}
+	if osc.w == os.Stdout {
+		t.Errorf("OSC52 writes to stdout, where the output may be piped")
+	}
+}
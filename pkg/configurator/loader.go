@@ -1,12 +1,27 @@
 package configurator
 
 import (
+	"encoding"
+	"errors"
+	"fmt"
 	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 	"gopkg.in/yaml.v3"
 )
 
+var (
+	ErrInvalidTarget   = errors.New("configurator: cfg must be a non-nil pointer to a struct")
+	ErrUnsupportedType = errors.New("configurator: unsupported field type")
+	ErrUnknownFormat   = errors.New("configurator: unknown config file format")
+	ErrCyclicConfig    = errors.New("configurator: struct type refers to itself")
+)
+
 func LoadFromYaml(fpath string, cfg interface{}) error {
 	b, err := os.ReadFile(fpath)
 	if err != nil {
@@ -32,3 +47,311 @@ func LoadFromEnv(fpath string, cfg interface{}) error {
 
 	return viper.Unmarshal(cfg)
 }
+
+// Field is a leaf of the configuration struct that sources can set.
+// Nested structs and pointers to structs are walked, their fields are the leaves.
+type Field struct {
+	// Path is the dotted path of yaml (or json) names, e.g. "app.channel"
+	Path string
+	// Env is the env tag, or the path in upper snake case, e.g. "APP_CHANNEL"
+	Env string
+	// Flag is the flag tag, or the path
+	Flag string
+	// Default is the default tag, set before any source
+	Default string
+	// Usage is the usage tag, shown in the flag help
+	Usage string
+
+	// envAlias is the mapstructure tag in upper case, e.g. "TELEGRAM_TOKEN"
+	envAlias string
+	// keys are the names a file may use for each level of the path
+	keys  [][]string
+	typ   reflect.Type
+	value func() reflect.Value
+}
+
+// Report maps the path of each field Load set to the source that set it
+// last: "default", a file path, "env" or "flags".
+type Report map[string]string
+
+// String lists the fields and their sources sorted by path
+func (r Report) String() string {
+	paths := make([]string, 0, len(r))
+	for path := range r {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var b strings.Builder
+	for _, path := range paths {
+		fmt.Fprintf(&b, "%s\t%s\n", path, r[path])
+	}
+	return b.String()
+}
+
+// Load fills cfg, a pointer to a struct, from the default tags of its fields
+// and then from each source in turn, a later source overriding the earlier
+// ones. Pass the sources in precedence order:
+//
+//	report, err := configurator.Load(&cfg,
+//		configurator.File("config.yaml"),
+//		configurator.DotEnv(".env"),
+//		configurator.Env(),
+//		configurator.Flags(flag.CommandLine, os.Args[1:]),
+//	)
+//
+// Files match fields by their yaml, json or mapstructure tag or their name,
+// environment sources by the env tag or the mapstructure tag in upper case.
+// A struct that refers to itself, e.g. through a Next *Node field, fails
+// with ErrCyclicConfig; tag such fields yaml:"-" to leave them out.
+// Integers are read in base 10, "010" is 10.
+func Load(cfg any, sources ...Source) (Report, error) {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w, got %T", ErrInvalidTarget, cfg)
+	}
+
+	fields, err := structFields(v.Elem().Type(), v.Elem, "", nil, map[reflect.Type]bool{})
+	if err != nil {
+		return nil, err
+	}
+
+	report := Report{}
+	for _, f := range fields {
+		if f.Default == "" {
+			continue
+		}
+		if err := setValue(f.value(), f.Default); err != nil {
+			return report, fmt.Errorf("default %s: %w", f.Path, err)
+		}
+		report[f.Path] = "default"
+	}
+
+	for _, src := range sources {
+		values, err := src.Values(fields)
+		if err != nil {
+			return report, fmt.Errorf("%s: %w", src.Name(), err)
+		}
+
+		for _, f := range fields {
+			raw, ok := values[f.Path]
+			if !ok || raw == nil {
+				continue
+			}
+			if err := setValue(f.value(), raw); err != nil {
+				return report, fmt.Errorf("%s: %s: %w", src.Name(), f.Path, err)
+			}
+			report[f.Path] = src.Name()
+		}
+	}
+	return report, nil
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// structFields lists the leaves of struct type t, value returns the struct
+// to set them on and allocates nil parents on the way. walking holds the
+// types from the root down to t, a type that contains itself is reported as
+// ErrCyclicConfig.
+func structFields(t reflect.Type, value func() reflect.Value, prefix string, keys [][]string, walking map[reflect.Type]bool) ([]Field, error) {
+	if walking[t] {
+		return nil, fmt.Errorf("%w: %s at %q", ErrCyclicConfig, t, prefix)
+	}
+	walking[t] = true
+	defer delete(walking, t)
+
+	var fields []Field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name := fieldName(sf)
+		if !sf.IsExported() || name == "-" {
+			continue
+		}
+
+		get := func() reflect.Value { return value().Field(i) }
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+		fieldKeys := append(append([][]string(nil), keys...), keyNames(sf))
+
+		if isStruct(sf.Type) {
+			if sf.Type.Kind() == reflect.Pointer {
+				get = func() reflect.Value {
+					p := value().Field(i)
+					if p.IsNil() {
+						p.Set(reflect.New(p.Type().Elem()))
+					}
+					return p.Elem()
+				}
+			}
+			typ := sf.Type
+			if typ.Kind() == reflect.Pointer {
+				typ = typ.Elem()
+			}
+
+			// embedded structs without a name of their own are inlined
+			var nested []Field
+			var err error
+			if sf.Anonymous && tagName(sf, "yaml") == "" && tagName(sf, "json") == "" {
+				nested, err = structFields(typ, get, prefix, keys, walking)
+			} else {
+				nested, err = structFields(typ, get, path, fieldKeys, walking)
+			}
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, nested...)
+			continue
+		}
+
+		f := Field{
+			Path:    path,
+			Env:     sf.Tag.Get("env"),
+			Flag:    sf.Tag.Get("flag"),
+			Default: sf.Tag.Get("default"),
+			Usage:   sf.Tag.Get("usage"),
+			keys:    fieldKeys,
+			typ:     sf.Type,
+			value:   get,
+		}
+		if f.Env == "" {
+			f.Env = strings.ToUpper(strings.ReplaceAll(path, ".", "_"))
+		}
+		if f.Flag == "" {
+			f.Flag = path
+		}
+		if alias := tagName(sf, "mapstructure"); alias != "" && alias != "-" {
+			f.envAlias = strings.ToUpper(alias)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// fieldName is the yaml name of a field, then its json name, then its lower
+// case Go name like yaml uses.
+func fieldName(sf reflect.StructField) string {
+	if name := tagName(sf, "yaml"); name != "" {
+		return name
+	}
+	if name := tagName(sf, "json"); name != "" {
+		return name
+	}
+	return strings.ToLower(sf.Name)
+}
+
+// keyNames are the names a config file may give a field
+func keyNames(sf reflect.StructField) []string {
+	names := []string{fieldName(sf)}
+	for _, key := range []string{"json", "mapstructure"} {
+		if name := tagName(sf, key); name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+	return append(names, sf.Name)
+}
+
+func tagName(sf reflect.StructField, key string) string {
+	name, _, _ := strings.Cut(sf.Tag.Get(key), ",")
+	return name
+}
+
+// isStruct reports whether t is a struct, or a pointer to one, that is walked
+// rather than set from text like time.Time.
+func isStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && !reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// setValue sets v from a string, or from a value a config file decoded to
+func setValue(v reflect.Value, raw any) error {
+	switch raw := raw.(type) {
+	case string:
+		return setString(v, raw)
+	case []any, map[string]any:
+		// lists and maps take the yaml route into slices, maps and structs
+		b, err := yaml.Marshal(raw)
+		if err != nil {
+			return err
+		}
+		return yaml.Unmarshal(b, v.Addr().Interface())
+	case time.Time:
+		return setString(v, raw.Format(time.RFC3339Nano))
+	case float64:
+		// JSON and YAML may write whole numbers as floats
+		return setString(v, strconv.FormatFloat(raw, 'f', -1, 64))
+	default:
+		return setString(v, fmt.Sprint(raw))
+	}
+}
+
+func setString(v reflect.Value, s string) error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setString(v.Elem(), s)
+	}
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == durationType {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return err
+			}
+			v.SetInt(int64(d))
+			return nil
+		}
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	case reflect.Slice:
+		// comma separated, as a single env var or flag holds a list
+		var parts []string
+		if s != "" {
+			parts = strings.Split(s, ",")
+		}
+		slice := reflect.MakeSlice(v.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := setString(slice.Index(i), strings.TrimSpace(part)); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedType, v.Type())
+	}
+	return nil
+}
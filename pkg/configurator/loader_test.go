@@ -0,0 +1,197 @@
+package configurator
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/vldcreation/helpme-package/pkg/trackclipboard"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	fpath := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(fpath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return fpath
+}
+
+func TestLoadLayers(t *testing.T) {
+	yamlFile := writeFile(t, "config.yaml", `
+app:
+  channel: local
+  idle: 5s
+  debug: true
+file:
+  path: /tmp
+  name: clip.txt
+telegram:
+  token: from-yaml
+`)
+	dotEnv := writeFile(t, ".env", "APP_CHANNEL=telegram\nTELEGRAM_TOKEN=from-dotenv\n")
+	t.Setenv("APP_IDLE", "1m")
+	t.Setenv("CHAT_ID", "42")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	args := []string{"-file.name", "flag.txt", "-app.debug=false"}
+
+	var cfg trackclipboard.Config
+	report, err := Load(&cfg, File(yamlFile), DotEnv(dotEnv), Env(), Flags(fs, args))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	want := trackclipboard.Config{
+		App:      &trackclipboard.APPConfig{Channel: "telegram", Idle: time.Minute, Debug: false},
+		File:     &trackclipboard.FileConfig{Path: "/tmp", Name: "flag.txt"},
+		Telegram: &trackclipboard.TelegramConfig{Token: "from-dotenv", ChatID: "42"},
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("Load() = %+v %+v %+v, want %+v %+v %+v", cfg.App, cfg.File, cfg.Telegram, want.App, want.File, want.Telegram)
+	}
+
+	wantReport := Report{
+		"app.channel":      dotEnv,
+		"app.idle":         "env",
+		"app.debug":        "flags",
+		"file.path":        yamlFile,
+		"file.name":        "flags",
+		"telegram.token":   dotEnv,
+		"telegram.chat_id": "env",
+	}
+	if !reflect.DeepEqual(report, wantReport) {
+		t.Errorf("Load() report = %v, want %v", report, wantReport)
+	}
+}
+
+type serverConfig struct {
+	Host    string        `json:"host" default:"localhost"`
+	Port    int           `json:"port" default:"8080"`
+	Timeout time.Duration `json:"timeout" default:"30s"`
+	Tags    []string      `json:"tags"`
+	Limits  struct {
+		Rate  float64 `json:"rate" default:"1.5"`
+		Burst uint    `json:"burst"`
+	} `json:"limits"`
+}
+
+func TestLoadFormats(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "config.json", content: `{"port": 9090, "tags": ["a", "b"], "limits": {"burst": 10}}`},
+		{name: "config.toml", content: "port = 9090\ntags = [\"a\", \"b\"]\n[limits]\nburst = 10\n"},
+		{name: "config.yml", content: "port: 9090\ntags: [a, b]\nlimits:\n  burst: 10\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg serverConfig
+			report, err := Load(&cfg, File(writeFile(t, tt.name, tt.content)))
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			if cfg.Host != "localhost" || cfg.Port != 9090 || cfg.Timeout != 30*time.Second {
+				t.Errorf("Load() = %+v", cfg)
+			}
+			if !reflect.DeepEqual(cfg.Tags, []string{"a", "b"}) || cfg.Limits.Rate != 1.5 || cfg.Limits.Burst != 10 {
+				t.Errorf("Load() = %+v", cfg)
+			}
+			if report["host"] != "default" || report["limits.rate"] != "default" {
+				t.Errorf("Load() report = %v, want defaults for host and limits.rate", report)
+			}
+		})
+	}
+}
+
+func TestLoadFlagsDefined(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("host", "", "defined by the caller")
+
+	var cfg serverConfig
+	if _, err := Load(&cfg, Flags(fs, []string{"-port", "9090"})); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	// the second Load finds every flag defined already
+	cfg = serverConfig{}
+	report, err := Load(&cfg, Flags(fs, []string{"-host", "example.com", "-port", "9091"}))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Host != "example.com" || cfg.Port != 9091 {
+		t.Errorf("Load() = %+v", cfg)
+	}
+	if report["host"] != "flags" || report["port"] != "flags" || report["timeout"] != "default" {
+		t.Errorf("Load() report = %v", report)
+	}
+
+	// flags of the earlier Loads are not given again
+	cfg = serverConfig{}
+	report, err = Load(&cfg, Flags(fs, nil))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Port != 8080 || report["port"] != "default" {
+		t.Errorf("Load() port = %d from %q, want 8080 from default", cfg.Port, report["port"])
+	}
+	if cfg.Host != "localhost" || report["host"] != "default" {
+		t.Errorf("Load() host = %q from %q, want the default", cfg.Host, report["host"])
+	}
+}
+
+func TestLoadDecimal(t *testing.T) {
+	t.Setenv("PORT", "010")
+	t.Setenv("LIMITS_BURST", "08")
+
+	var cfg serverConfig
+	if _, err := Load(&cfg, Env()); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Port != 10 || cfg.Limits.Burst != 8 {
+		t.Errorf("Load() port = %d, burst = %d, want 10 and 8", cfg.Port, cfg.Limits.Burst)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	var cfg serverConfig
+	if _, err := Load(cfg); !errors.Is(err, ErrInvalidTarget) {
+		t.Errorf("Load() error = %v, want %v", err, ErrInvalidTarget)
+	}
+	if _, err := Load(&cfg, File(writeFile(t, "config.ini", "port=1"))); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("Load() error = %v, want %v", err, ErrUnknownFormat)
+	}
+
+	type node struct {
+		Name string `yaml:"name"`
+		Next *node  `yaml:"next"`
+	}
+	if _, err := Load(&node{}); !errors.Is(err, ErrCyclicConfig) {
+		t.Errorf("Load() error = %v, want %v", err, ErrCyclicConfig)
+	}
+	type list struct {
+		Name string `yaml:"name"`
+		Next *list  `yaml:"-"`
+	}
+	if _, err := Load(&list{}); err != nil {
+		t.Errorf("Load() with the cycle tagged out error = %v", err)
+	}
+
+	t.Setenv("PORT", "not a number")
+	if _, err := Load(&cfg, Env()); err == nil {
+		t.Errorf("Load() with an invalid PORT expected error")
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if _, err := Load(&cfg, Flags(fs, []string{"-unknown"})); err == nil {
+		t.Errorf("Load() with an unknown flag expected error")
+	}
+}
@@ -0,0 +1,259 @@
+package configurator
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/subosito/gotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// Source is a layer of configuration for Load
+type Source interface {
+	// Name identifies the source in a Report
+	Name() string
+	// Values returns the values the source has for fields, keyed by
+	// Field.Path. Values are strings or what a config file decoded to.
+	Values(fields []Field) (map[string]any, error)
+}
+
+type fileSource struct {
+	path string
+}
+
+// File reads a YAML, JSON or TOML file, the format follows the extension
+func File(path string) Source {
+	return &fileSource{path: path}
+}
+
+func (s *fileSource) Name() string {
+	return s.path
+}
+
+func (s *fileSource) Values(fields []Field) (map[string]any, error) {
+	b, err := os.ReadFile(filepath.Clean(s.path))
+	if err != nil {
+		return nil, err
+	}
+
+	var doc map[string]any
+	switch ext := strings.ToLower(filepath.Ext(s.path)); ext {
+	case ".yaml", ".yml", ".json":
+		// YAML is a superset of JSON, one decoder reads both
+		err = yaml.Unmarshal(b, &doc)
+	case ".toml":
+		err = toml.Unmarshal(b, &doc)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, ext)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]any{}
+	for _, f := range fields {
+		if v, ok := lookupKeys(doc, f.keys); ok {
+			values[f.Path] = v
+		}
+	}
+	return values, nil
+}
+
+// lookupKeys walks doc down a path, keys holds the names each level may have
+func lookupKeys(doc map[string]any, keys [][]string) (any, bool) {
+	var cur any = doc
+	for _, names := range keys {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+
+		found := false
+		for _, name := range names {
+			for key, v := range m {
+				if strings.EqualFold(key, name) {
+					cur, found = v, true
+					break
+				}
+			}
+			if found {
+				break
+			}
+		}
+		if !found {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+type dotEnvSource struct {
+	path string
+}
+
+// DotEnv reads KEY=value lines from a .env file, the keys are matched like
+// environment variables.
+func DotEnv(path string) Source {
+	return &dotEnvSource{path: path}
+}
+
+func (s *dotEnvSource) Name() string {
+	return s.path
+}
+
+func (s *dotEnvSource) Values(fields []Field) (map[string]any, error) {
+	env, err := gotenv.Read(filepath.Clean(s.path))
+	if err != nil {
+		return nil, err
+	}
+
+	return envValues(fields, func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}), nil
+}
+
+type envSource struct{}
+
+// Env reads the process environment
+func Env() Source {
+	return envSource{}
+}
+
+func (envSource) Name() string {
+	return "env"
+}
+
+func (envSource) Values(fields []Field) (map[string]any, error) {
+	return envValues(fields, os.LookupEnv), nil
+}
+
+// envValues looks up each field by its env name, then by its mapstructure alias
+func envValues(fields []Field, lookup func(key string) (string, bool)) map[string]any {
+	values := map[string]any{}
+	for _, f := range fields {
+		for _, key := range []string{f.Env, f.envAlias} {
+			if key == "" || key == "-" {
+				continue
+			}
+			if v, ok := lookup(key); ok {
+				values[f.Path] = v
+				break
+			}
+		}
+	}
+	return values
+}
+
+type flagSource struct {
+	fs   *flag.FlagSet
+	args []string
+}
+
+// Flags defines a flag per field on fs, named by Field.Flag, and parses args.
+// Flags fs already has, defined by the caller or by an earlier Load, are
+// read as they are. Only flags args sets count, a flag set by an earlier
+// Parse of fs is not reported again. A nil fs is a new flag set.
+func Flags(fs *flag.FlagSet, args []string) Source {
+	if fs == nil {
+		fs = flag.NewFlagSet(filepath.Base(os.Args[0]), flag.ContinueOnError)
+	}
+	return &flagSource{fs: fs, args: args}
+}
+
+func (s *flagSource) Name() string {
+	return "flags"
+}
+
+func (s *flagSource) Values(fields []Field) (map[string]any, error) {
+	for _, f := range fields {
+		if f.Flag == "-" || s.fs.Lookup(f.Flag) != nil {
+			continue
+		}
+
+		typ := f.typ
+		if typ.Kind() == reflect.Pointer {
+			typ = typ.Elem()
+		}
+		s.fs.Var(&flagValue{value: f.Default, isBool: typ.Kind() == reflect.Bool}, f.Flag, f.Usage)
+	}
+
+	// fs remembers the flags of every Parse, only the ones args set count
+	given := map[string]bool{}
+	for _, f := range fields {
+		fl := s.fs.Lookup(f.Flag)
+		if f.Flag == "-" || fl == nil {
+			continue
+		}
+		if _, ok := fl.Value.(givenValue); ok {
+			continue
+		}
+		orig := fl.Value
+		fl.Value = givenValue{Value: orig, name: fl.Name, given: given}
+		defer func() { fl.Value = orig }()
+	}
+
+	if err := s.fs.Parse(s.args); err != nil {
+		return nil, err
+	}
+
+	values := map[string]any{}
+	for _, f := range fields {
+		if f.Flag != "-" && given[f.Flag] {
+			values[f.Path] = s.fs.Lookup(f.Flag).Value.String()
+		}
+	}
+	return values, nil
+}
+
+// givenValue records the flags a single Parse sets
+type givenValue struct {
+	flag.Value
+	name  string
+	given map[string]bool
+}
+
+func (v givenValue) String() string {
+	// the flag package calls String on a zero value for the help
+	if v.Value == nil {
+		return ""
+	}
+	return v.Value.String()
+}
+
+func (v givenValue) Set(s string) error {
+	v.given[v.name] = true
+	return v.Value.Set(s)
+}
+
+func (v givenValue) IsBoolFlag() bool {
+	b, ok := v.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// flagValue is a flag Load defined, it shows the default tag in the help
+type flagValue struct {
+	value  string
+	isBool bool
+}
+
+func (v *flagValue) String() string {
+	if v == nil {
+		return ""
+	}
+	return v.value
+}
+
+func (v *flagValue) Set(s string) error {
+	v.value = s
+	return nil
+}
+
+func (v *flagValue) IsBoolFlag() bool {
+	return v.isBool
+}
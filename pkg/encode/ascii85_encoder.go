@@ -0,0 +1,43 @@
+package encode
+
+import (
+	"encoding/ascii85"
+	"io"
+)
+
+// Ascii85Encoder is the Adobe ascii85 encoding without the <~ ~> delimiters
+type Ascii85Encoder struct{}
+
+func NewAscii85Encoder() FormatCodec {
+	return &Ascii85Encoder{}
+}
+
+// Encode writes the encoding of src into dst, which must hold ascii85.MaxEncodedLen(len(src)) bytes
+func (a *Ascii85Encoder) Encode(dst []byte, src []byte) {
+	ascii85.Encode(dst, src)
+}
+
+func (a *Ascii85Encoder) EncodeToString(src []byte) string {
+	dst := make([]byte, ascii85.MaxEncodedLen(len(src)))
+	n := ascii85.Encode(dst, src)
+	return string(dst[:n])
+}
+
+func (a *Ascii85Encoder) NewEncoder(w io.Writer) io.WriteCloser {
+	return ascii85.NewEncoder(w)
+}
+
+func (a *Ascii85Encoder) Decode(dst []byte, src []byte) (int, error) {
+	n, _, err := ascii85.Decode(dst, src, true)
+	return n, err
+}
+
+func (a *Ascii85Encoder) DecodeString(src string) ([]byte, error) {
+	// "z" expands to four zero bytes
+	dst := make([]byte, 4*len(src))
+	n, _, err := ascii85.Decode(dst, []byte(src), true)
+	if err != nil {
+		return nil, err
+	}
+	return dst[:n], nil
+}
@@ -1,12 +1,20 @@
 package encode
 
-import "encoding/base32"
+import (
+	"encoding/base32"
+	"io"
+)
+
+const (
+	stdBase32Alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567"
+	hexBase32Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUV"
+)
 
 type Base32Encoder struct {
 	enc *base32.Encoding
 }
 
-func NewBase32Encoder(src string) FormatEncoder {
+func NewBase32Encoder(src string) FormatCodec {
 	var encoder *base32.Encoding = base32.StdEncoding
 	if len(src) == 32 {
 		encoder = base32.NewEncoding(src)
@@ -19,10 +27,18 @@ func (b *Base32Encoder) Encode(dst []byte, src []byte) {
 	b.enc.Encode(dst, src)
 }
 
-func (b *Base32Encoder) Decode(dst []byte, src []byte) {
-	b.enc.Decode(dst, src)
-}
-
 func (b *Base32Encoder) EncodeToString(src []byte) string {
 	return b.enc.EncodeToString(src)
 }
+
+func (b *Base32Encoder) NewEncoder(w io.Writer) io.WriteCloser {
+	return base32.NewEncoder(b.enc, w)
+}
+
+func (b *Base32Encoder) Decode(dst []byte, src []byte) (int, error) {
+	return b.enc.Decode(dst, src)
+}
+
+func (b *Base32Encoder) DecodeString(src string) ([]byte, error) {
+	return b.enc.DecodeString(src)
+}
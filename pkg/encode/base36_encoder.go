@@ -0,0 +1,30 @@
+package encode
+
+// Base36Alphabet holds the digits and lower case letters, decoding ignores case
+const Base36Alphabet = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+type Base36Encoder struct {
+	codec *radixCodec
+}
+
+func NewBase36Encoder() FormatCodec {
+	return &Base36Encoder{codec: newRadixCodec("base36", Base36Alphabet, true)}
+}
+
+// Encode copies the encoding of src into dst, which must be large enough
+func (b *Base36Encoder) Encode(dst []byte, src []byte) {
+	copy(dst, b.codec.encode(src))
+}
+
+func (b *Base36Encoder) EncodeToString(src []byte) string {
+	return string(b.codec.encode(src))
+}
+
+func (b *Base36Encoder) Decode(dst []byte, src []byte) (int, error) {
+	decoded, err := b.codec.decode(src)
+	return decodeInto(dst, decoded, err)
+}
+
+func (b *Base36Encoder) DecodeString(src string) ([]byte, error) {
+	return b.codec.decode([]byte(src))
+}
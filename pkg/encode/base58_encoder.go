@@ -0,0 +1,30 @@
+package encode
+
+// Base58Alphabet is the Bitcoin base58 alphabet, without 0, O, I and l
+const Base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+type Base58Encoder struct {
+	codec *radixCodec
+}
+
+func NewBase58Encoder() FormatCodec {
+	return &Base58Encoder{codec: newRadixCodec("base58", Base58Alphabet, false)}
+}
+
+// Encode copies the encoding of src into dst, which must be large enough
+func (b *Base58Encoder) Encode(dst []byte, src []byte) {
+	copy(dst, b.codec.encode(src))
+}
+
+func (b *Base58Encoder) EncodeToString(src []byte) string {
+	return string(b.codec.encode(src))
+}
+
+func (b *Base58Encoder) Decode(dst []byte, src []byte) (int, error) {
+	decoded, err := b.codec.decode(src)
+	return decodeInto(dst, decoded, err)
+}
+
+func (b *Base58Encoder) DecodeString(src string) ([]byte, error) {
+	return b.codec.decode([]byte(src))
+}
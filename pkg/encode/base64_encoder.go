@@ -1,28 +1,50 @@
 package encode
 
-import "encoding/base64"
+import (
+	"encoding/base64"
+	"io"
+)
+
+const (
+	stdBase64Alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+	urlBase64Alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+)
 
 type Base64Encoder struct {
 	enc *base64.Encoding
+	// std is set for the padded standard alphabet, the only one data URIs accept
+	std bool
 }
 
-func NewBase64Encoder(src string) FormatEncoder {
+func NewBase64Encoder(src string) FormatCodec {
 	var encoder *base64.Encoding = base64.StdEncoding
 	if len(src) == 64 {
 		encoder = base64.NewEncoding(src)
 	}
 
-	return &Base64Encoder{enc: encoder}
+	return &Base64Encoder{enc: encoder, std: len(src) != 64 || src == stdBase64Alphabet}
 }
 
 func (b *Base64Encoder) Encode(dst []byte, src []byte) {
 	b.enc.Encode(dst, src)
 }
 
-func (b *Base64Encoder) Decode(dst []byte, src []byte) {
-	b.enc.Decode(dst, src)
-}
-
 func (b *Base64Encoder) EncodeToString(src []byte) string {
 	return b.enc.EncodeToString(src)
 }
+
+func (b *Base64Encoder) NewEncoder(w io.Writer) io.WriteCloser {
+	return base64.NewEncoder(b.enc, w)
+}
+
+func (b *Base64Encoder) Decode(dst []byte, src []byte) (int, error) {
+	return b.enc.Decode(dst, src)
+}
+
+func (b *Base64Encoder) DecodeString(src string) ([]byte, error) {
+	return b.enc.DecodeString(src)
+}
+
+func (b *Base64Encoder) DataURIEncoding() (string, bool) {
+	return ";base64", b.std
+}
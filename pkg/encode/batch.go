@@ -0,0 +1,318 @@
+package encode
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+type batchConfig struct {
+	parallelism int
+	format      string
+	encoderOpts []EncoderOpt
+}
+
+type BatchOpt func(*batchConfig)
+
+// WithParallelism bounds how many files are encoded at once, defaults to the number of CPUs
+func WithParallelism(n int) BatchOpt {
+	return func(c *batchConfig) {
+		if n > 0 {
+			c.parallelism = n
+		}
+	}
+}
+
+// WithBatchFormat names the format encoder of every file, see Names. Defaults to base64.
+func WithBatchFormat(name string) BatchOpt {
+	return func(c *batchConfig) {
+		c.format = name
+	}
+}
+
+// WithBatchEncoderOpts applies opts to the FileEncoder of every file,
+// e.g. WithMimePolicy or WithCompression.
+func WithBatchEncoderOpts(opts ...EncoderOpt) BatchOpt {
+	return func(c *batchConfig) {
+		c.encoderOpts = append(c.encoderOpts, opts...)
+	}
+}
+
+// Manifest maps the files of an encoded directory to their payloads
+type Manifest struct {
+	// Format is the format encoder name the payloads are encoded with
+	Format      string `json:"format" yaml:"format"`
+	Compression string `json:"compression,omitempty" yaml:"compression,omitempty"`
+	Encrypted   bool   `json:"encrypted,omitempty" yaml:"encrypted,omitempty"`
+	// Files is keyed by the slash separated path relative to the encoded root
+	Files map[string]ManifestFile `json:"files" yaml:"files"`
+	// Skipped lists files the MIME policy rejected with the reason
+	Skipped map[string]string `json:"skipped,omitempty" yaml:"skipped,omitempty"`
+}
+
+// ManifestFile describes a single encoded file
+type ManifestFile struct {
+	MimeType string `json:"mime_type" yaml:"mime_type"`
+	Size     int64  `json:"size" yaml:"size"`
+	// SHA256 is the hex digest of the original content
+	SHA256  string `json:"sha256" yaml:"sha256"`
+	Payload string `json:"payload" yaml:"payload"`
+}
+
+// EncodeBatch encodes every regular file below root, or every file matching
+// root when it is a glob pattern, and collects the results in a Manifest.
+// Files rejected by the MIME policy are listed as skipped, any other failure
+// aborts the batch.
+func EncodeBatch(ctx context.Context, root string, opts ...BatchOpt) (*Manifest, error) {
+	cfg := &batchConfig{parallelism: runtime.NumCPU(), format: "base64"}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if _, err := Lookup(cfg.format); err != nil {
+		return nil, err
+	}
+
+	base, files, err := batchFiles(root)
+	if err != nil {
+		return nil, err
+	}
+
+	// the stages are read back from a probe so Rebuild knows how to reverse them
+	probe := &FileEncoder{}
+	probe.ApplyOpt(cfg.encoderOpts...)
+	manifest := &Manifest{
+		Format:      cfg.format,
+		Compression: probe.compression,
+		Encrypted:   probe.encryption.enabled(),
+		Files:       make(map[string]ManifestFile, len(files)),
+		Skipped:     map[string]string{},
+	}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+	)
+	sem := make(chan struct{}, cfg.parallelism)
+	for _, path := range files {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return nil, ctx.Err()
+		}
+
+		wg.Add(1)
+		go func(path string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			rel, err := filepath.Rel(base, path)
+			if err == nil {
+				rel = filepath.ToSlash(rel)
+			}
+			var file ManifestFile
+			if err == nil {
+				file, err = encodeBatchFile(path, cfg)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case errors.Is(err, ErrMimeTypeNotAllowed), errors.Is(err, ErrInvalidExtension):
+				manifest.Skipped[rel] = skipReason(err)
+			case err != nil:
+				if firstErr == nil {
+					firstErr = err
+				}
+			default:
+				manifest.Files[rel] = file
+			}
+		}(path)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return manifest, nil
+}
+
+// skipReason is the manifest entry for a skipped file, whose path is the key
+func skipReason(err error) string {
+	var opErr *OpError
+	if !errors.As(err, &opErr) || opErr.Kind == nil {
+		return err.Error()
+	}
+	if opErr.MimeType == "" {
+		return opErr.Kind.Message
+	}
+	return fmt.Sprintf("%s (%s)", opErr.Kind.Message, opErr.MimeType)
+}
+
+func encodeBatchFile(path string, cfg *batchConfig) (ManifestFile, error) {
+	formatEncoder, err := Lookup(cfg.format)
+	if err != nil {
+		return ManifestFile{}, err
+	}
+
+	// the file is read once, the payload, MIME type and digest share its bytes
+	content, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return ManifestFile{}, &OpError{Op: "encode", Path: path, Kind: ErrInvalidFile, Err: err}
+	}
+
+	enc := &FileEncoder{fpath: path, formatEncoder: formatEncoder}
+	enc.ApplyOpt(cfg.encoderOpts...)
+	var payload strings.Builder
+	mimeType, err := enc.encodeContent(&payload, bytes.NewReader(content), path, int64(len(content)))
+	if err != nil {
+		return ManifestFile{}, wrapOpError(err, "encode", path, enc.formatEncoder)
+	}
+
+	sum := sha256.Sum256(content)
+	return ManifestFile{
+		MimeType: mimeType,
+		Size:     int64(len(content)),
+		SHA256:   hex.EncodeToString(sum[:]),
+		Payload:  payload.String(),
+	}, nil
+}
+
+// batchFiles resolves root to the files to encode and the directory their
+// manifest paths are relative to.
+func batchFiles(root string) (string, []string, error) {
+	if info, err := os.Stat(root); err == nil && info.IsDir() {
+		var files []string
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.Type().IsRegular() {
+				files = append(files, path)
+			}
+			return nil
+		})
+		return root, files, err
+	}
+
+	matches, err := filepath.Glob(root)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var files []string
+	for _, match := range matches {
+		if info, err := os.Stat(match); err == nil && info.Mode().IsRegular() {
+			files = append(files, match)
+		}
+	}
+	if len(files) == 0 {
+		return "", nil, fmt.Errorf("%w: no files match %s", ErrInvalidFilePath, root)
+	}
+	sort.Strings(files)
+
+	// paths are relative to the directory of the pattern before its first meta
+	// character, the placeholder keeps a trailing partial name out of it
+	base := root
+	if i := strings.IndexAny(root, `*?[\`); i >= 0 {
+		base = root[:i] + "_"
+	}
+	return filepath.Dir(base), files, nil
+}
+
+// WriteJSON writes the manifest as indented JSON
+func (m *Manifest) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(m)
+}
+
+// WriteYAML writes the manifest as YAML
+func (m *Manifest) WriteYAML(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	defer enc.Close()
+	return enc.Encode(m)
+}
+
+// ReadManifest reads a manifest written by WriteJSON or WriteYAML
+func ReadManifest(r io.Reader) (*Manifest, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// YAML is a superset of JSON, one decoder reads both
+	var m Manifest
+	if err := yaml.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidManifest, err)
+	}
+	if m.Format == "" {
+		return nil, fmt.Errorf("%w: missing format", ErrInvalidManifest)
+	}
+	return &m, nil
+}
+
+// Rebuild decodes every file of the manifest below dir and verifies its
+// size and digest. Encrypted manifests need WithEncryption in opts.
+func (m *Manifest) Rebuild(dir string, opts ...EncoderOpt) error {
+	formatEncoder, err := Lookup(m.Format)
+	if err != nil {
+		return err
+	}
+
+	probe := &FileEncoder{}
+	probe.ApplyOpt(opts...)
+	if m.Encrypted && !probe.encryption.enabled() {
+		return fmt.Errorf("%w: the manifest is encrypted", ErrDecryptionFailed)
+	}
+
+	paths := make([]string, 0, len(m.Files))
+	for path := range m.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		if !filepath.IsLocal(filepath.FromSlash(path)) {
+			return &OpError{Op: "rebuild", Path: path, Kind: ErrInvalidManifest, Err: errors.New("path escapes the target directory")}
+		}
+	}
+
+	for _, path := range paths {
+		file := m.Files[path]
+		content, err := decodePayload(file.Payload, formatEncoder, m.Compression, probe.encryption)
+		if err != nil {
+			return wrapOpError(err, "decode", path, formatEncoder)
+		}
+
+		sum := sha256.Sum256(content)
+		if int64(len(content)) != file.Size || hex.EncodeToString(sum[:]) != file.SHA256 {
+			return &OpError{Op: "rebuild", Path: path, Kind: ErrChecksumMismatch}
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, content, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package encode
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func writeAssets(t *testing.T) string {
+	t.Helper()
+	gif, err := os.ReadFile("../../test_data/portrait.gif")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"portrait.gif":     gif,
+		"img/logo.png":     append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0x42}, 300)...),
+		"docs/readme.txt":  []byte("asset bundle\n"),
+		"docs/icon.svg":    []byte("<svg></svg>"),
+		"docs/deep/a.txt":  []byte("nested\n"),
+		"docs/deep/b.json": []byte(`{"b":true}`),
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func manifestPaths(m *Manifest) []string {
+	var paths []string
+	for path := range m.Files {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+func TestEncodeBatch(t *testing.T) {
+	dir := writeAssets(t)
+
+	m, err := EncodeBatch(context.Background(), dir, WithParallelism(2))
+	if err != nil {
+		t.Fatalf("EncodeBatch() error = %v", err)
+	}
+
+	want := []string{"docs/deep/a.txt", "docs/deep/b.json", "docs/readme.txt", "img/logo.png", "portrait.gif"}
+	if got := manifestPaths(m); !slices.Equal(got, want) {
+		t.Errorf("Files = %v, want %v", got, want)
+	}
+	if _, ok := m.Skipped["docs/icon.svg"]; !ok || len(m.Skipped) != 1 {
+		t.Errorf("Skipped = %v, want docs/icon.svg", m.Skipped)
+	}
+
+	gif := m.Files["portrait.gif"]
+	if gif.MimeType != "image/gif" || gif.Size == 0 || len(gif.SHA256) != 64 {
+		t.Errorf("Files[portrait.gif] = %+v", gif)
+	}
+
+	for _, format := range []string{"json", "yaml"} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			write := m.WriteJSON
+			if format == "yaml" {
+				write = m.WriteYAML
+			}
+			if err := write(&buf); err != nil {
+				t.Fatalf("write error = %v", err)
+			}
+
+			read, err := ReadManifest(&buf)
+			if err != nil {
+				t.Fatalf("ReadManifest() error = %v", err)
+			}
+
+			out := t.TempDir()
+			if err := read.Rebuild(out); err != nil {
+				t.Fatalf("Rebuild() error = %v", err)
+			}
+			for _, path := range want {
+				got, _ := os.ReadFile(filepath.Join(out, path))
+				orig, _ := os.ReadFile(filepath.Join(dir, path))
+				if !bytes.Equal(got, orig) {
+					t.Errorf("rebuilt %s differs from the original", path)
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeBatchGlob(t *testing.T) {
+	dir := writeAssets(t)
+
+	m, err := EncodeBatch(context.Background(), filepath.Join(dir, "docs", "*.txt"), WithBatchFormat("base58"))
+	if err != nil {
+		t.Fatalf("EncodeBatch() error = %v", err)
+	}
+	if got := manifestPaths(m); !slices.Equal(got, []string{"readme.txt"}) || m.Format != "base58" {
+		t.Errorf("EncodeBatch() = %v in %s", got, m.Format)
+	}
+
+	if _, err := EncodeBatch(context.Background(), filepath.Join(dir, "*.none")); !errors.Is(err, ErrInvalidFilePath) {
+		t.Errorf("EncodeBatch() error = %v, want %v", err, ErrInvalidFilePath)
+	}
+}
+
+func TestEncodeBatchStages(t *testing.T) {
+	dir := writeAssets(t)
+
+	m, err := EncodeBatch(context.Background(), dir, WithBatchEncoderOpts(
+		WithMimePolicy(MimePolicy{Allow: []string{"image/*"}}),
+		WithCompression("gzip"),
+		WithEncryption("bundle key"),
+	))
+	if err != nil {
+		t.Fatalf("EncodeBatch() error = %v", err)
+	}
+	if got := manifestPaths(m); !slices.Equal(got, []string{"docs/icon.svg", "img/logo.png", "portrait.gif"}) {
+		t.Errorf("Files = %v", got)
+	}
+	if m.Compression != "gzip" || !m.Encrypted {
+		t.Errorf("Manifest stages = %q, encrypted %v", m.Compression, m.Encrypted)
+	}
+	// the manifest describes the original file, not the sealed payload
+	if logo := m.Files["img/logo.png"]; logo.MimeType != "image/png" {
+		t.Errorf("logo.png MimeType = %q, want image/png", logo.MimeType)
+	}
+
+	if err := m.Rebuild(t.TempDir()); !errors.Is(err, ErrDecryptionFailed) {
+		t.Errorf("Rebuild() without passphrase error = %v, want %v", err, ErrDecryptionFailed)
+	}
+	out := t.TempDir()
+	if err := m.Rebuild(out, WithEncryption("bundle key")); err != nil {
+		t.Fatalf("Rebuild() error = %v", err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(out, "docs", "icon.svg")); string(got) != "<svg></svg>" {
+		t.Errorf("rebuilt icon.svg = %q", got)
+	}
+}
+
+func TestManifestRebuildErrors(t *testing.T) {
+	payload := NewBase64Encoder("").EncodeToString([]byte("hello"))
+	tests := []struct {
+		name    string
+		file    string
+		sha256  string
+		wantErr error
+	}{
+		{name: "path escapes", file: "../evil.txt", sha256: "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", wantErr: ErrInvalidManifest},
+		{name: "absolute path", file: "/etc/evil.txt", sha256: "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", wantErr: ErrInvalidManifest},
+		{name: "checksum", file: "hello.txt", sha256: "00", wantErr: ErrChecksumMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &Manifest{Format: "base64", Files: map[string]ManifestFile{
+				tt.file: {MimeType: "text/plain", Size: 5, SHA256: tt.sha256, Payload: payload},
+			}}
+			if err := m.Rebuild(t.TempDir()); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Rebuild() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := ReadManifest(bytes.NewReader([]byte(`{"files": {}}`))); !errors.Is(err, ErrInvalidManifest) {
+		t.Errorf("ReadManifest() error = %v, want %v", err, ErrInvalidManifest)
+	}
+}
@@ -0,0 +1,76 @@
+package encode
+
+import (
+	"bytes"
+	"context"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+
+	"github.com/vldcreation/helpme-package/pkg/clipboard"
+)
+
+// writeClipboard writes data to cb, or to the clipboard Detect finds when cb is nil
+func writeClipboard(cb clipboard.Clipboard, format clipboard.Format, data []byte) error {
+	if cb == nil {
+		var err error
+		if cb, err = clipboard.Detect(); err != nil {
+			return err
+		}
+	}
+	return cb.Write(context.Background(), format, data)
+}
+
+// ClipboardMode says what a FileEncoder puts on the clipboard with WithCopyToClipboard
+type ClipboardMode int
+
+const (
+	// ClipboardText copies the encoded result as text
+	ClipboardText ClipboardMode = iota
+	// ClipboardImage copies a PNG or JPEG file as an image, JPEGs are converted to PNG
+	ClipboardImage
+)
+
+// copyToClipboardAs writes the encoded file or the image itself to the clipboard
+func (i *FileEncoder) copyToClipboardAs(mode ClipboardMode, encoded string) error {
+	if mode == ClipboardText {
+		return writeClipboard(i.clipboard, clipboard.FormatText, []byte(encoded))
+	}
+
+	raw, err := os.ReadFile(filepath.Clean(i.fpath))
+	if err != nil {
+		return &OpError{Op: "encode", Path: i.fpath, Kind: ErrInvalidFile, Err: err}
+	}
+
+	mimeType, err := i.detectMimeType(bytes.NewReader(raw), i.fpath, int64(len(raw)))
+	if err != nil {
+		return err
+	}
+	img, err := clipboardImage(mimeType, raw)
+	if err != nil {
+		return err
+	}
+
+	return writeClipboard(i.clipboard, clipboard.FormatImage, img)
+}
+
+// clipboardImage returns an image as the PNG clipboards expect
+func clipboardImage(mimeType string, raw []byte) ([]byte, error) {
+	switch baseMimeType(mimeType) {
+	case "image/png":
+		return raw, nil
+	case "image/jpeg":
+		img, err := jpeg.Decode(bytes.NewReader(raw))
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, &OpError{Kind: ErrClipboardImage, MimeType: mimeType}
+	}
+}
@@ -0,0 +1,144 @@
+package encode
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+
+	"github.com/vldcreation/helpme-package/pkg/clipboard"
+)
+
+// ClipboardEncoder encodes the image on the clipboard, e.g. a screenshot,
+// without saving it to a file first. Decode reads encoded text from the
+// clipboard instead, such as a data URI copied from a web page.
+type ClipboardEncoder struct {
+	clipboard       clipboard.Clipboard
+	copyToClipboard bool
+	withMimeType    bool
+	formatEncoder   FormatEncoder
+	compression     string
+	encryption      encryption
+}
+
+func NewClipboardEncoder(opts ...EncoderOpt) Encoder {
+	c := &ClipboardEncoder{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+func (c *ClipboardEncoder) ApplyOpt(opts ...EncoderOpt) {
+	for _, opt := range opts {
+		opt(c)
+	}
+}
+
+// Encode reads the clipboard image and encodes it, with WithCopyToClipboard
+// the result replaces the image on the clipboard.
+func (c *ClipboardEncoder) Encode() (string, error) {
+	if c.formatEncoder == nil {
+		return "", ErrEncoderNotSet
+	}
+
+	encoded, err := c.encode()
+	if err != nil {
+		return "", wrapOpError(err, "encode", "", c.formatEncoder)
+	}
+	return encoded, nil
+}
+
+func (c *ClipboardEncoder) encode() (string, error) {
+	cb, err := c.board()
+	if err != nil {
+		return "", err
+	}
+
+	img, err := cb.Read(context.Background(), clipboard.FormatImage)
+	if err != nil {
+		return "", err
+	}
+	if len(img) == 0 {
+		return "", &OpError{Kind: ErrClipboardEmpty}
+	}
+
+	mimeType, err := DetectMimeType(bytes.NewReader(img), int64(len(img)))
+	if err != nil {
+		return "", err
+	}
+
+	data, err := sealStages(img, c.compression, c.encryption)
+	if err != nil {
+		return "", err
+	}
+
+	encoded := c.formatEncoder.EncodeToString(data)
+	if c.withMimeType {
+		prefix, err := dataURIPrefix(c.payloadMimeType(mimeType), c.formatEncoder)
+		if err != nil {
+			return "", &OpError{MimeType: mimeType, Err: err}
+		}
+		encoded = prefix + encoded
+	}
+
+	if c.copyToClipboard {
+		if err := cb.Write(context.Background(), clipboard.FormatText, []byte(encoded)); err != nil {
+			return "", err
+		}
+	}
+	return encoded, nil
+}
+
+// EncodeTo writes the encoded clipboard image to w
+func (c *ClipboardEncoder) EncodeTo(w io.Writer) error {
+	encoded, err := c.Encode()
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, encoded)
+	return err
+}
+
+// Decode decodes the text on the clipboard, data URIs as their header says
+func (c *ClipboardEncoder) Decode() ([]byte, error) {
+	decoded, err := c.decode()
+	if err != nil {
+		return nil, wrapOpError(err, "decode", "", c.formatEncoder)
+	}
+	return decoded, nil
+}
+
+func (c *ClipboardEncoder) decode() ([]byte, error) {
+	cb, err := c.board()
+	if err != nil {
+		return nil, err
+	}
+
+	text, err := cb.Read(context.Background(), clipboard.FormatText)
+	if err != nil {
+		return nil, err
+	}
+	if len(text) == 0 {
+		return nil, &OpError{Kind: ErrSourceTextNotSet, Err: errors.New("no text on the clipboard")}
+	}
+
+	return decodePayload(string(text), c.formatEncoder, c.compression, c.encryption)
+}
+
+func (c *ClipboardEncoder) board() (clipboard.Clipboard, error) {
+	if c.clipboard != nil {
+		return c.clipboard, nil
+	}
+	return clipboard.Detect()
+}
+
+// payloadMimeType is the type of the encoded data once the stages ran
+func (c *ClipboardEncoder) payloadMimeType(mimeType string) string {
+	// a compressed payload starts with its stage header, it is no longer the source type
+	if c.encryption.enabled() || c.compression != "" {
+		return "application/octet-stream"
+	}
+	return mimeType
+}
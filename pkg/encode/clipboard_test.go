@@ -0,0 +1,104 @@
+package encode
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vldcreation/helpme-package/pkg/clipboard"
+)
+
+func writeImages(t *testing.T) (pngPath, jpegPath string) {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	img.Set(1, 1, color.RGBA{R: 255, A: 255})
+
+	dir := t.TempDir()
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+	pngPath = filepath.Join(dir, "dot.png")
+	if err := os.WriteFile(pngPath, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	buf.Reset()
+	jpeg.Encode(&buf, img, nil)
+	jpegPath = filepath.Join(dir, "dot.jpg")
+	if err := os.WriteFile(jpegPath, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return pngPath, jpegPath
+}
+
+func TestFileEncoderClipboardImage(t *testing.T) {
+	pngPath, jpegPath := writeImages(t)
+	ctx := context.Background()
+
+	for _, fpath := range []string{pngPath, jpegPath} {
+		t.Run(filepath.Ext(fpath), func(t *testing.T) {
+			cb := clipboard.NewMemory()
+			encoded, err := NewFileEncoder(fpath, WithFormatEncoder(NewBase64Encoder("")), WithCopyToClipboard(true),
+				WithClipboard(cb), WithClipboardMode(ClipboardImage)).Encode()
+			if err != nil || encoded == "" {
+				t.Fatalf("Encode() = %q, %v", encoded, err)
+			}
+
+			got, _ := cb.Read(ctx, clipboard.FormatImage)
+			if _, err := png.Decode(bytes.NewReader(got)); err != nil {
+				t.Errorf("clipboard image is not a PNG: %v", err)
+			}
+			if text, _ := cb.Read(ctx, clipboard.FormatText); text != nil {
+				t.Errorf("clipboard text = %q, want none", text)
+			}
+		})
+	}
+
+	t.Run("not an image", func(t *testing.T) {
+		_, err := NewFileEncoder("../../test_data/portrait.gif", WithFormatEncoder(NewBase64Encoder("")), WithCopyToClipboard(true),
+			WithClipboard(clipboard.NewMemory()), WithClipboardMode(ClipboardImage)).Encode()
+		if !errors.Is(err, ErrClipboardImage) {
+			t.Errorf("Encode() error = %v, want %v", err, ErrClipboardImage)
+		}
+	})
+}
+
+func TestClipboardEncoder(t *testing.T) {
+	pngPath, _ := writeImages(t)
+	raw, _ := os.ReadFile(pngPath)
+	ctx := context.Background()
+
+	cb := clipboard.NewMemory()
+	if _, err := NewClipboardEncoder(WithClipboard(cb), WithFormatEncoder(NewBase64Encoder(""))).Encode(); !errors.Is(err, ErrClipboardEmpty) {
+		t.Errorf("Encode() error = %v, want %v", err, ErrClipboardEmpty)
+	}
+
+	cb.Write(ctx, clipboard.FormatImage, raw)
+	enc := NewClipboardEncoder(WithClipboard(cb), WithFormatEncoder(NewBase64Encoder("")), WithMimeType(true), WithCopyToClipboard(true))
+	encoded, err := enc.Encode()
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if !strings.HasPrefix(encoded, "data:image/png;base64,") {
+		t.Errorf("Encode() = %q, want a PNG data URI", encoded)
+	}
+	if text, _ := cb.Read(ctx, clipboard.FormatText); string(text) != encoded {
+		t.Errorf("clipboard text = %q, want the encoded image", text)
+	}
+
+	// the data URI now on the clipboard decodes back to the image
+	got, err := enc.Decode()
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if !bytes.Equal(got, raw) {
+		t.Errorf("Decode() differs from the clipboard image")
+	}
+}
@@ -0,0 +1,204 @@
+package encode
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/lzw"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"strings"
+)
+
+// compressionMagic prefixes every compressed payload, it is followed by a
+// byte naming the algorithm and the frame version. The payload ends with the
+// CRC-32 of the compressed stream, so plain data starting with the same bytes
+// is not taken for a compressed payload and decoding needs no WithCompression.
+const (
+	compressionMagic   = "\x00hz"
+	compressionVersion = "\x01"
+)
+
+// compressionTrailerSize is the size of the CRC-32 closing a compressed payload
+const compressionTrailerSize = crc32.Size
+
+// compression is a stage run over the source before the format encoder
+type compression struct {
+	name string
+	// header is written before the compressed stream
+	header []byte
+	// detect recognizes a raw stream without header, e.g. made by other tools
+	detect    func(b []byte) bool
+	newWriter func(w io.Writer) (io.WriteCloser, error)
+	newReader func(r io.Reader) (io.ReadCloser, error)
+}
+
+var compressions = []*compression{
+	{
+		name:   "gzip",
+		header: []byte(compressionMagic + "g" + compressionVersion),
+		detect: func(b []byte) bool {
+			return len(b) >= 3 && b[0] == 0x1f && b[1] == 0x8b && b[2] == 0x08
+		},
+		newWriter: func(w io.Writer) (io.WriteCloser, error) {
+			return gzip.NewWriter(w), nil
+		},
+		newReader: func(r io.Reader) (io.ReadCloser, error) {
+			return gzip.NewReader(r)
+		},
+	},
+	{
+		name:   "zlib",
+		header: []byte(compressionMagic + "z" + compressionVersion),
+		detect: func(b []byte) bool {
+			// deflate method with a header checksum, see RFC 1950
+			return len(b) >= 2 && b[0]&0x0f == 8 && (uint16(b[0])<<8|uint16(b[1]))%31 == 0
+		},
+		newWriter: func(w io.Writer) (io.WriteCloser, error) {
+			return zlib.NewWriter(w), nil
+		},
+		newReader: zlib.NewReader,
+	},
+	{
+		name:   "deflate",
+		header: []byte(compressionMagic + "d" + compressionVersion),
+		newWriter: func(w io.Writer) (io.WriteCloser, error) {
+			return flate.NewWriter(w, flate.DefaultCompression)
+		},
+		newReader: func(r io.Reader) (io.ReadCloser, error) {
+			return flate.NewReader(r), nil
+		},
+	},
+	{
+		name:   "lzw",
+		header: []byte(compressionMagic + "l" + compressionVersion),
+		newWriter: func(w io.Writer) (io.WriteCloser, error) {
+			return lzw.NewWriter(w, lzw.LSB, 8), nil
+		},
+		newReader: func(r io.Reader) (io.ReadCloser, error) {
+			return lzw.NewReader(r, lzw.LSB, 8), nil
+		},
+	},
+}
+
+// Compressions returns the names WithCompression accepts
+func Compressions() []string {
+	names := make([]string, 0, len(compressions))
+	for _, c := range compressions {
+		names = append(names, c.name)
+	}
+	return names
+}
+
+func lookupCompression(name string) (*compression, error) {
+	for _, c := range compressions {
+		if strings.EqualFold(c.name, name) {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownCompression, name)
+}
+
+// detectCompression finds the compression b was produced with from its
+// header and checksum and returns it with the compressed stream. With raw, a
+// stream without header is recognized by its own signature where the format has one.
+func detectCompression(b []byte, raw bool) (*compression, []byte, error) {
+	for _, c := range compressions {
+		if !bytes.HasPrefix(b, c.header) || len(b) < len(c.header)+compressionTrailerSize {
+			continue
+		}
+		stream, trailer := b[len(c.header):len(b)-compressionTrailerSize], b[len(b)-compressionTrailerSize:]
+		if crc32.ChecksumIEEE(stream) == binary.BigEndian.Uint32(trailer) {
+			return c, stream, nil
+		}
+	}
+
+	// self describing formats are checked last, their signatures are shorter
+	if raw {
+		for _, c := range compressions {
+			if c.detect != nil && c.detect(b) {
+				return c, b, nil
+			}
+		}
+	}
+	return nil, nil, ErrCompressionNotDetected
+}
+
+// isCompressed reports whether b is a payload of the compression stage
+func isCompressed(b []byte) bool {
+	_, _, err := detectCompression(b, false)
+	return err == nil
+}
+
+// compressWriter returns a writer compressing into w, Close flushes the
+// compressed stream and its checksum but does not close w.
+func (c *compression) compressWriter(w io.Writer) (io.WriteCloser, error) {
+	if _, err := w.Write(c.header); err != nil {
+		return nil, err
+	}
+
+	sum := crc32.NewIEEE()
+	cw, err := c.newWriter(io.MultiWriter(w, sum))
+	if err != nil {
+		return nil, err
+	}
+	return &compressWriter{WriteCloser: cw, w: w, sum: sum}, nil
+}
+
+// compressWriter appends the checksum of the compressed stream on Close
+type compressWriter struct {
+	io.WriteCloser
+	w   io.Writer
+	sum hash.Hash32
+}
+
+func (c *compressWriter) Close() error {
+	if err := c.WriteCloser.Close(); err != nil {
+		return err
+	}
+	_, err := c.w.Write(c.sum.Sum(nil))
+	return err
+}
+
+func (c *compression) compress(src []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := c.compressWriter(&buf)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := w.Write(src); err != nil {
+		w.Close()
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// decompress detects the compression of src and returns the original data,
+// raw accepts gzip and zlib streams without header as well.
+func decompress(src []byte, raw bool) ([]byte, error) {
+	c, stream, err := detectCompression(src, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := c.newReader(bytes.NewReader(stream))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", c.name, err)
+	}
+	defer r.Close()
+
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", c.name, err)
+	}
+	return b, nil
+}
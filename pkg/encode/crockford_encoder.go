@@ -0,0 +1,47 @@
+package encode
+
+import (
+	"encoding/base32"
+	"io"
+	"strings"
+)
+
+// CrockfordAlphabet is Douglas Crockford's base32 alphabet, without I, L, O and U
+const CrockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// crockfordDecodeReplacer applies the Crockford decoding rules: hyphens are
+// ignored, O reads as 0 and I and L read as 1.
+var crockfordDecodeReplacer = strings.NewReplacer("-", "", "O", "0", "I", "1", "L", "1")
+
+// CrockfordBase32Encoder encodes without padding, decoding ignores case
+type CrockfordBase32Encoder struct {
+	enc *base32.Encoding
+}
+
+func NewCrockfordBase32Encoder() FormatCodec {
+	return &CrockfordBase32Encoder{enc: base32.NewEncoding(CrockfordAlphabet).WithPadding(base32.NoPadding)}
+}
+
+func (c *CrockfordBase32Encoder) Encode(dst []byte, src []byte) {
+	c.enc.Encode(dst, src)
+}
+
+func (c *CrockfordBase32Encoder) EncodeToString(src []byte) string {
+	return c.enc.EncodeToString(src)
+}
+
+func (c *CrockfordBase32Encoder) NewEncoder(w io.Writer) io.WriteCloser {
+	return base32.NewEncoder(c.enc, w)
+}
+
+func (c *CrockfordBase32Encoder) Decode(dst []byte, src []byte) (int, error) {
+	return c.enc.Decode(dst, []byte(c.normalize(string(src))))
+}
+
+func (c *CrockfordBase32Encoder) DecodeString(src string) ([]byte, error) {
+	return c.enc.DecodeString(c.normalize(src))
+}
+
+func (c *CrockfordBase32Encoder) normalize(src string) string {
+	return crockfordDecodeReplacer.Replace(strings.ToUpper(src))
+}
@@ -0,0 +1,168 @@
+package encode
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// DataURIEncoder is implemented by format encoders whose output can be the
+// data of an RFC 2397 data URI.
+type DataURIEncoder interface {
+	// DataURIEncoding returns the encoding marker, ";base64" or "" for
+	// percent encoding, ok is false when the encoder is configured in a
+	// way data URIs do not allow, e.g. a URL-safe alphabet.
+	DataURIEncoding() (marker string, ok bool)
+}
+
+// DataURI is a parsed RFC 2397 data URI
+type DataURI struct {
+	// MediaType is the type without parameters, e.g. "image/png"
+	MediaType string
+	// Params holds the media type parameters, e.g. charset
+	Params map[string]string
+	Base64 bool
+	Data   []byte
+}
+
+// ParseDataURI decodes a data URI into its media type and data. A missing
+// media type defaults to "text/plain;charset=US-ASCII" as the RFC specifies.
+func ParseDataURI(uri string) (*DataURI, error) {
+	uri = strings.TrimSpace(uri)
+	if len(uri) < 5 || !strings.EqualFold(uri[:5], "data:") {
+		return nil, fmt.Errorf("%w: missing data: scheme", ErrInvalidDataURI)
+	}
+
+	header, data, ok := strings.Cut(uri[5:], ",")
+	if !ok {
+		return nil, fmt.Errorf("%w: missing comma before the data", ErrInvalidDataURI)
+	}
+
+	d := &DataURI{Params: map[string]string{}}
+	parts := strings.Split(header, ";")
+	if last := len(parts) - 1; last > 0 && strings.EqualFold(parts[last], "base64") {
+		d.Base64 = true
+		parts = parts[:last]
+	}
+
+	if mediaType := strings.TrimSpace(parts[0]); mediaType != "" {
+		if !strings.Contains(mediaType, "/") {
+			return nil, fmt.Errorf("%w: invalid media type %q", ErrInvalidDataURI, mediaType)
+		}
+		d.MediaType = strings.ToLower(mediaType)
+	}
+	for _, param := range parts[1:] {
+		key, value, ok := strings.Cut(param, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: invalid parameter %q", ErrInvalidDataURI, param)
+		}
+		if unescaped, err := url.PathUnescape(value); err == nil {
+			value = unescaped
+		}
+		d.Params[strings.ToLower(strings.TrimSpace(key))] = value
+	}
+
+	if d.MediaType == "" {
+		d.MediaType = "text/plain"
+		if len(d.Params) == 0 {
+			d.Params["charset"] = "US-ASCII"
+		}
+	}
+
+	unescaped, err := url.PathUnescape(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDataURI, err)
+	}
+	if !d.Base64 {
+		d.Data = []byte(unescaped)
+		return d, nil
+	}
+
+	// padding is often dropped by hand written URIs
+	enc := base64.StdEncoding
+	if len(unescaped)%4 != 0 {
+		enc = base64.RawStdEncoding
+	}
+	if d.Data, err = enc.DecodeString(unescaped); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDataURI, err)
+	}
+	return d, nil
+}
+
+// ContentType returns the media type with its parameters, e.g. "text/plain;charset=utf-8"
+func (d *DataURI) ContentType() string {
+	return formatMediaType(d.MediaType, d.Params)
+}
+
+// String encodes the data URI, base64 or percent encoded as Base64 says
+func (d *DataURI) String() string {
+	if d.Base64 {
+		return "data:" + d.ContentType() + ";base64," + base64.StdEncoding.EncodeToString(d.Data)
+	}
+	return "data:" + d.ContentType() + "," + string(percentEncode(nil, d.Data))
+}
+
+// dataURIPrefix returns the data URI header for mimeType encoded with enc
+func dataURIPrefix(mimeType string, enc FormatEncoder) (string, error) {
+	uriEncoder, ok := enc.(DataURIEncoder)
+	if !ok {
+		return "", fmt.Errorf("%w: %T", ErrDataURINotSupported, enc)
+	}
+	marker, ok := uriEncoder.DataURIEncoding()
+	if !ok {
+		return "", fmt.Errorf("%w: %T is not configured as standard base64", ErrDataURINotSupported, enc)
+	}
+
+	base, params := splitMediaType(mimeType)
+	if marker == "" && !isTextMimeType(base) {
+		return "", fmt.Errorf("%w: percent encoding is meant for text types, not %s", ErrDataURINotSupported, base)
+	}
+
+	return "data:" + formatMediaType(base, params) + marker + ",", nil
+}
+
+// isTextMimeType reports whether a type holds text, which reads well percent encoded
+func isTextMimeType(mimeType string) bool {
+	switch {
+	case strings.HasPrefix(mimeType, "text/"),
+		strings.HasSuffix(mimeType, "+xml"),
+		strings.HasSuffix(mimeType, "+json"):
+		return true
+	}
+	switch mimeType {
+	case "application/json", "application/xml", "application/javascript":
+		return true
+	}
+	return false
+}
+
+// splitMediaType splits "text/plain; charset=utf-8" into its type and parameters
+func splitMediaType(mimeType string) (string, map[string]string) {
+	parts := strings.Split(mimeType, ";")
+	params := map[string]string{}
+	for _, param := range parts[1:] {
+		if key, value, ok := strings.Cut(param, "="); ok {
+			params[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+		}
+	}
+	return baseMimeType(parts[0]), params
+}
+
+// formatMediaType joins a type and its parameters the way RFC 2397 writes
+// them, without spaces and with parameters in a stable order.
+func formatMediaType(mimeType string, params map[string]string) string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(mimeType)
+	for _, key := range keys {
+		b.WriteString(";" + key + "=" + string(percentEncode(nil, []byte(params[key]))))
+	}
+	return b.String()
+}
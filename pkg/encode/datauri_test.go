@@ -0,0 +1,100 @@
+package encode
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseDataURI(t *testing.T) {
+	tests := []struct {
+		name        string
+		uri         string
+		contentType string
+		data        string
+		wantErr     error
+	}{
+		{name: "base64", uri: "data:image/png;base64,iVBORw0K", contentType: "image/png", data: "\x89PNG\r\n"},
+		{name: "percent", uri: "data:text/plain;charset=utf-8,hello%2C%20world", contentType: "text/plain;charset=utf-8", data: "hello, world"},
+		{name: "default media type", uri: "data:,A%20brief%20note", contentType: "text/plain;charset=US-ASCII", data: "A brief note"},
+		{name: "params only", uri: "data:;charset=utf-8,x", contentType: "text/plain;charset=utf-8", data: "x"},
+		{name: "unpadded base64", uri: "DATA:text/plain;base64,aGk", contentType: "text/plain", data: "hi"},
+		{name: "no scheme", uri: "image/png;base64,aGk=", wantErr: ErrInvalidDataURI},
+		{name: "no comma", uri: "data:text/plain;base64", wantErr: ErrInvalidDataURI},
+		{name: "bad base64", uri: "data:;base64,!!!!", wantErr: ErrInvalidDataURI},
+		{name: "bad media type", uri: "data:png,x", wantErr: ErrInvalidDataURI},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDataURI(tt.uri)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseDataURI() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.ContentType() != tt.contentType {
+				t.Errorf("ContentType() = %q, want %q", got.ContentType(), tt.contentType)
+			}
+			if string(got.Data) != tt.data {
+				t.Errorf("Data = %q, want %q", got.Data, tt.data)
+			}
+
+			again, err := ParseDataURI(got.String())
+			if err != nil || !bytes.Equal(again.Data, got.Data) || again.ContentType() != got.ContentType() {
+				t.Errorf("ParseDataURI(String()) = %+v, %v", again, err)
+			}
+		})
+	}
+}
+
+func TestFileEncoderDataURI(t *testing.T) {
+	dir := t.TempDir()
+	text := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(text, []byte("package main // héllo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	base64URL, _ := Lookup("base64url")
+	wrapped, _ := Lookup("base64", WithLineWrap(76))
+	tests := []struct {
+		name    string
+		fpath   string
+		encoder FormatEncoder
+		want    string
+		wantErr error
+	}{
+		{name: "base64 image", fpath: "../../test_data/portrait.gif", encoder: NewBase64Encoder("")},
+		{name: "base64 text", fpath: text, encoder: NewBase64Encoder(""), want: "data:text/plain;charset=utf-8;base64,cGFja2FnZSBtYWluIC8vIGjDqWxsbwo="},
+		{name: "percent text", fpath: text, encoder: NewPercentEncoder(), want: "data:text/plain;charset=utf-8,package%20main%20%2F%2F%20h%C3%A9llo%0A"},
+		{name: "percent image", fpath: "../../test_data/portrait.gif", encoder: NewPercentEncoder(), wantErr: ErrDataURINotSupported},
+		{name: "url safe base64", fpath: text, encoder: base64URL, wantErr: ErrDataURINotSupported},
+		{name: "wrapped base64", fpath: text, encoder: wrapped, wantErr: ErrDataURINotSupported},
+		{name: "hex", fpath: text, encoder: NewHexEncoder(), wantErr: ErrDataURINotSupported},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewFileEncoder(tt.fpath, WithFormatEncoder(tt.encoder), WithMimeType(true)).Encode()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Encode() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if tt.want != "" && got != tt.want {
+				t.Errorf("Encode() = %q, want %q", got, tt.want)
+			}
+
+			uri, err := ParseDataURI(got)
+			if err != nil {
+				t.Fatalf("ParseDataURI() error = %v", err)
+			}
+			content, _ := os.ReadFile(tt.fpath)
+			if !bytes.Equal(uri.Data, content) {
+				t.Errorf("ParseDataURI() data differs from the file")
+			}
+		})
+	}
+}
@@ -1,7 +1,14 @@
 package encode
 
+import "io"
+
 type Encoder interface {
 	Encode() (string, error)
+	// EncodeTo writes the encoded result to w, streaming where the format allows it
+	EncodeTo(w io.Writer) error
+	// Decode reverses Encode: the source is treated as encoded text and decoded
+	// with the format encoder, which must implement FormatDecoder.
+	Decode() ([]byte, error)
 	ApplyOpt(...EncoderOpt)
 }
 
@@ -11,5 +18,20 @@ type FormatEncoder interface {
 }
 
 type FormatDecoder interface {
-	Decode(dst []byte, src string) (int, error)
+	// Decode decodes src into dst and returns the number of bytes written
+	Decode(dst []byte, src []byte) (int, error)
+	DecodeString(src string) ([]byte, error)
+}
+
+// FormatCodec is a FormatEncoder that can also decode what it encoded
+type FormatCodec interface {
+	FormatEncoder
+	FormatDecoder
+}
+
+// StreamEncoder is implemented by format encoders that can encode a stream
+// of unknown length, e.g. a large file, in constant memory.
+type StreamEncoder interface {
+	// NewEncoder returns a writer encoding into w, Close flushes any partial block
+	NewEncoder(w io.Writer) io.WriteCloser
 }
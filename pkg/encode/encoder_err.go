@@ -1,15 +1,26 @@
 package encode
 
-import "fmt"
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
 
+// Error is a sentinel error, compare with errors.Is
 type Error struct {
 	Type    string
 	Message string
 }
 
+// NewError returns a sentinel named after T, interface types such as any
+// leave the type out of the message.
 func NewError[T any](errMsg string) *Error {
+	var typ string
+	if t := reflect.TypeFor[T](); t.Kind() != reflect.Interface {
+		typ = t.String()
+	}
 	return &Error{
-		Type:    fmt.Sprintf("%T", *new(T)),
+		Type:    typ,
 		Message: errMsg,
 	}
 }
@@ -23,10 +34,111 @@ func (e *Error) Error() string {
 }
 
 var (
-	ErrInvalidExtension = NewError[FileEncoder]("invalid extension")
-	ErrInvalidFilePath  = NewError[FileEncoder]("invalid file path")
-	ErrInvalidFile      = NewError[FileEncoder]("invalid file")
-	ErrFilePathNotSet   = NewError[FileEncoder]("file path not set")
-	ErrSourceTextNotSet = NewError[TextEncoder]("source text not set")
-	ErrEncoderNotSet    = NewError[any]("encoder not set")
+	ErrInvalidExtension   = NewError[FileEncoder]("invalid extension")
+	ErrInvalidFilePath    = NewError[FileEncoder]("invalid file path")
+	ErrInvalidFile        = NewError[FileEncoder]("invalid file")
+	ErrFilePathNotSet     = NewError[FileEncoder]("file path not set")
+	ErrMimeTypeNotAllowed = NewError[FileEncoder]("MIME type not allowed")
+	ErrMimeTypeMismatch   = NewError[FileEncoder]("MIME type does not match the file extension")
+	ErrClipboardImage     = NewError[FileEncoder]("only PNG and JPEG images can be copied as an image")
+	ErrClipboardEmpty     = NewError[ClipboardEncoder]("no image on the clipboard")
+	ErrSourceTextNotSet   = NewError[TextEncoder]("source text not set")
+	ErrEncoderNotSet      = NewError[any]("encoder not set")
+
+	ErrDecoderNotSupported  = NewError[any]("format encoder does not support decoding")
+	ErrUnknownFormatEncoder = NewError[any]("unknown format encoder")
+	ErrInvalidFormatOption  = NewError[any]("invalid format encoder option")
+
+	ErrUnknownCompression     = NewError[any]("unknown compression")
+	ErrCompressionNotDetected = NewError[any]("compression not detected")
+
+	ErrInvalidDataURI      = NewError[any]("invalid data URI")
+	ErrDataURINotSupported = NewError[any]("format encoder cannot produce a data URI")
+
+	ErrUnknownValueCodec = NewError[ValueEncoder]("unknown value codec")
+
+	ErrInvalidManifest  = NewError[Manifest]("invalid manifest")
+	ErrChecksumMismatch = NewError[any]("checksum mismatch")
+
+	ErrUnknownDigest       = NewError[Hasher]("unknown digest")
+	ErrInvalidDigestOption = NewError[Hasher]("invalid digest option")
+	ErrInvalidChecksumFile = NewError[Hasher]("invalid checksum file")
+	ErrDigestMismatch      = NewError[Hasher]("checksum line uses another digest")
+
+	ErrUnknownCipher       = NewError[any]("unknown cipher")
+	ErrInvalidEnvelope     = NewError[any]("data is not an encrypted envelope")
+	ErrUnsupportedEnvelope = NewError[any]("unsupported encrypted envelope")
+	ErrDecryptionFailed    = NewError[any]("decryption failed: wrong passphrase or tampered data")
 )
+
+// OpError describes a failed operation on a file or encoded data. Kind is
+// the sentinel for the kind of failure and Err the underlying error, e.g. an
+// *fs.PathError, errors.Is matches both and errors.As finds the OpError.
+type OpError struct {
+	// Op is the operation, e.g. "encode", "decode" or "hash"
+	Op string
+	// Path is the file operated on, empty for text and the clipboard
+	Path string
+	// MimeType is the type detected in the file, if it got that far
+	MimeType string
+	// Encoder is the type of the format encoder, e.g. "encode.Base64Encoder"
+	Encoder string
+	Kind    *Error
+	Err     error
+}
+
+func (e *OpError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Op)
+	if e.Path != "" {
+		b.WriteString(" " + e.Path)
+	}
+	if e.Kind != nil {
+		b.WriteString(": " + e.Kind.Message)
+	}
+	if e.MimeType != "" {
+		b.WriteString(" (" + e.MimeType + ")")
+	}
+	if e.Err != nil {
+		// the fields already say what the type prefix of a sentinel would
+		if sentinel, ok := e.Err.(*Error); ok {
+			b.WriteString(": " + sentinel.Message)
+		} else {
+			b.WriteString(": " + e.Err.Error())
+		}
+	}
+	return b.String()
+}
+
+func (e *OpError) Unwrap() []error {
+	var errs []error
+	if e.Kind != nil {
+		errs = append(errs, e.Kind)
+	}
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+	return errs
+}
+
+// wrapOpError completes the OpError err is, or wraps err in a new one
+func wrapOpError(err error, op, path string, formatEncoder FormatEncoder) error {
+	if err == nil {
+		return nil
+	}
+
+	opErr, ok := err.(*OpError)
+	if !ok {
+		opErr = &OpError{Err: err}
+	}
+	if opErr.Op == "" {
+		opErr.Op = op
+	}
+	if opErr.Path == "" {
+		opErr.Path = path
+	}
+	if opErr.Encoder == "" && formatEncoder != nil {
+		opErr.Encoder = strings.TrimPrefix(fmt.Sprintf("%T", formatEncoder), "*")
+	}
+	return opErr
+}
@@ -0,0 +1,69 @@
+package encode
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpError(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, content []byte) string {
+		fpath := filepath.Join(dir, name)
+		if err := os.WriteFile(fpath, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return fpath
+	}
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+	tests := []struct {
+		name     string
+		fpath    string
+		wantErrs []error
+		mimeType string
+	}{
+		{name: "missing file", fpath: filepath.Join(dir, "missing.png"), wantErrs: []error{ErrInvalidFilePath, fs.ErrNotExist}},
+		{name: "empty file", fpath: write("empty.png", nil), wantErrs: []error{ErrInvalidFile, io.EOF}},
+		{name: "extension mismatch", fpath: write("report.pdf", png), wantErrs: []error{ErrInvalidExtension, ErrMimeTypeMismatch}, mimeType: "image/png"},
+		{name: "not allowed", fpath: write("logo.svg", []byte("<svg></svg>")), wantErrs: []error{ErrMimeTypeNotAllowed}, mimeType: "image/svg+xml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewFileEncoder(tt.fpath, WithFormatEncoder(NewBase64Encoder(""))).Encode()
+			for _, want := range tt.wantErrs {
+				if !errors.Is(err, want) {
+					t.Errorf("Encode() error = %v, want %v", err, want)
+				}
+			}
+
+			var opErr *OpError
+			if !errors.As(err, &opErr) {
+				t.Fatalf("Encode() error = %T, want *OpError", err)
+			}
+			if opErr.Op != "encode" || opErr.Path != tt.fpath || opErr.Encoder != "encode.Base64Encoder" {
+				t.Errorf("OpError = %+v", opErr)
+			}
+			if opErr.MimeType != tt.mimeType {
+				t.Errorf("OpError.MimeType = %q, want %q", opErr.MimeType, tt.mimeType)
+			}
+		})
+	}
+}
+
+func TestOpErrorMessage(t *testing.T) {
+	err := &OpError{Op: "encode", Path: "logo.svg", MimeType: "image/svg+xml", Kind: ErrMimeTypeNotAllowed}
+	if got, want := err.Error(), "encode logo.svg: MIME type not allowed (image/svg+xml)"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+
+	if got, want := ErrEncoderNotSet.Error(), "encoder not set"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if got, want := ErrSourceTextNotSet.Error(), "encode.TextEncoder: source text not set"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
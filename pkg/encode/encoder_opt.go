@@ -1,5 +1,7 @@
 package encode
 
+import "github.com/vldcreation/helpme-package/pkg/clipboard"
+
 type EncoderOpt func(T any)
 
 func WithFpath(fpath string) EncoderOpt {
@@ -19,6 +21,23 @@ func WithCopyToClipboard(copyToClipboard bool) EncoderOpt {
 			T.copyToClipboard = copyToClipboard
 		case *TextEncoder:
 			T.copyToClipboard = copyToClipboard
+		case *ClipboardEncoder:
+			T.copyToClipboard = copyToClipboard
+		}
+	}
+}
+
+// WithClipboard sets the clipboard WithCopyToClipboard writes to,
+// by default the one clipboard.Detect finds.
+func WithClipboard(cb clipboard.Clipboard) EncoderOpt {
+	return func(T any) {
+		switch T := T.(type) {
+		case *FileEncoder:
+			T.clipboard = cb
+		case *TextEncoder:
+			T.clipboard = cb
+		case *ClipboardEncoder:
+			T.clipboard = cb
 		}
 	}
 }
@@ -30,16 +49,139 @@ func WithFormatEncoder(encoder FormatEncoder) EncoderOpt {
 			T.formatEncoder = encoder
 		case *TextEncoder:
 			T.formatEncoder = encoder
+		case *ClipboardEncoder:
+			T.formatEncoder = encoder
+		case *Hasher:
+			T.formatEncoder = encoder
+		case *ValueEncoder:
+			T.formatEncoder = encoder
+		}
+	}
+}
+
+// WithMimePolicy replaces DefaultMimePolicy for a FileEncoder
+func WithMimePolicy(policy MimePolicy) EncoderOpt {
+	return func(T any) {
+		i, ok := T.(*FileEncoder)
+		if !ok {
+			return
 		}
+		i.mimePolicy = &policy
 	}
 }
 
 func WithMimeType(mimeType bool) EncoderOpt {
+	return func(T any) {
+		switch T := T.(type) {
+		case *FileEncoder:
+			T.withMimeType = mimeType
+		case *ClipboardEncoder:
+			T.withMimeType = mimeType
+		}
+	}
+}
+
+// WithClipboardMode picks what WithCopyToClipboard copies for a FileEncoder,
+// the encoded text by default.
+func WithClipboardMode(mode ClipboardMode) EncoderOpt {
 	return func(T any) {
 		i, ok := T.(*FileEncoder)
 		if !ok {
 			return
 		}
-		i.withMimeType = mimeType
+		i.clipboardMode = mode
+	}
+}
+
+// WithCompression compresses the source with the named algorithm, one of
+// Compressions, before it is format encoded. Decode detects the algorithm
+// from the compressed header, so any name enables decompression there.
+func WithCompression(name string) EncoderOpt {
+	return func(T any) {
+		switch T := T.(type) {
+		case *FileEncoder:
+			T.compression = name
+		case *TextEncoder:
+			T.compression = name
+		case *ClipboardEncoder:
+			T.compression = name
+		case *ValueEncoder:
+			T.compression = name
+		}
+	}
+}
+
+// WithEncryption seals the source with a key derived from passphrase before
+// it is format encoded, Decode opens it with the same passphrase.
+func WithEncryption(passphrase string) EncoderOpt {
+	return func(T any) {
+		switch T := T.(type) {
+		case *FileEncoder:
+			T.encryption.passphrase = passphrase
+		case *TextEncoder:
+			T.encryption.passphrase = passphrase
+		case *ClipboardEncoder:
+			T.encryption.passphrase = passphrase
+		case *ValueEncoder:
+			T.encryption.passphrase = passphrase
+		}
+	}
+}
+
+// WithCipher picks the cipher WithEncryption seals with, one of Ciphers.
+// Decode reads the cipher from the envelope instead.
+func WithCipher(name string) EncoderOpt {
+	return func(T any) {
+		switch T := T.(type) {
+		case *FileEncoder:
+			T.encryption.cipher = name
+		case *TextEncoder:
+			T.encryption.cipher = name
+		case *ClipboardEncoder:
+			T.encryption.cipher = name
+		case *ValueEncoder:
+			T.encryption.cipher = name
+		}
+	}
+}
+
+// WithValueCodec picks how a ValueEncoder serializes values, one of ValueCodecs
+func WithValueCodec(name string) EncoderOpt {
+	return func(T any) {
+		i, ok := T.(*ValueEncoder)
+		if !ok {
+			return
+		}
+		i.codec = name
+	}
+}
+
+// WithDigest makes Encode return the digest of the source instead of the
+// encoded source, one of Digests. The digest is formatted with the format
+// encoder, hex without one.
+func WithDigest(name string) EncoderOpt {
+	return func(T any) {
+		switch T := T.(type) {
+		case *FileEncoder:
+			T.digest = name
+		case *TextEncoder:
+			T.digest = name
+		case *Hasher:
+			T.digest = name
+		}
+	}
+}
+
+// WithHMACKey turns the digest into an HMAC with key, BLAKE2b uses its own keyed mode
+func WithHMACKey(key []byte) EncoderOpt {
+	return func(T any) {
+		switch T := T.(type) {
+		case *FileEncoder:
+			T.hmacKey = key
+		case *TextEncoder:
+			T.hmacKey = key
+		case *Hasher:
+			T.hmacKey = key
+		}
 	}
 }
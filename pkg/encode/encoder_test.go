@@ -0,0 +1,386 @@
+package encode
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vldcreation/helpme-package/pkg/clipboard"
+)
+
+func TestFormatCodecRoundTrip(t *testing.T) {
+	codecs := []struct {
+		name  string
+		codec FormatCodec
+	}{
+		{name: "base64", codec: NewBase64Encoder("")},
+		{name: "base64 custom alphabet", codec: NewBase64Encoder("ZYXWVUTSRQPONMLKJIHGFEDCBAzyxwvutsrqponmlkjihgfedcba9876543210-_")},
+		{name: "base32", codec: NewBase32Encoder("")},
+		{name: "hex", codec: NewHexEncoder()},
+		{name: "gob", codec: NewGobEncoder()},
+	}
+	inputs := [][]byte{
+		{},
+		[]byte("f"),
+		[]byte("hello, world"),
+		{0x00, 0xff, 0x10, 0x80, 0x7f},
+	}
+
+	for _, tt := range codecs {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, src := range inputs {
+				encoded := tt.codec.EncodeToString(src)
+
+				got, err := tt.codec.DecodeString(encoded)
+				if err != nil {
+					t.Fatalf("DecodeString(%q) error = %v", encoded, err)
+				}
+				if !bytes.Equal(got, src) {
+					t.Errorf("DecodeString(EncodeToString(%q)) = %q", src, got)
+				}
+
+				dst := make([]byte, len(encoded))
+				n, err := tt.codec.Decode(dst, []byte(encoded))
+				if err != nil {
+					t.Fatalf("Decode(%q) error = %v", encoded, err)
+				}
+				if !bytes.Equal(dst[:n], src) {
+					t.Errorf("Decode(EncodeToString(%q)) = %q", src, dst[:n])
+				}
+
+				// repeated calls must not depend on each other
+				if again := tt.codec.EncodeToString(src); again != encoded {
+					t.Errorf("EncodeToString(%q) = %q on second call, want %q", src, again, encoded)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatCodecDecodeError(t *testing.T) {
+	codecs := map[string]FormatCodec{
+		"base64": NewBase64Encoder(""),
+		"base32": NewBase32Encoder(""),
+		"hex":    NewHexEncoder(),
+		"gob":    NewGobEncoder(),
+	}
+	for name, codec := range codecs {
+		if _, err := codec.DecodeString("!not encoded!"); err == nil {
+			t.Errorf("%s: DecodeString() expected error", name)
+		}
+	}
+}
+
+func TestTextEncoderDecode(t *testing.T) {
+	encoded, err := NewTextEncoder("hello", WithFormatEncoder(NewBase32Encoder(""))).Encode()
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	got, err := NewTextEncoder(encoded, WithFormatEncoder(NewBase32Encoder(""))).Decode()
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if string(got) != "hello" {
+		t.Errorf("Decode() = %q, want %q", got, "hello")
+	}
+
+	if _, err := NewTextEncoder(encoded).Decode(); err != ErrEncoderNotSet {
+		t.Errorf("Decode() error = %v, want %v", err, ErrEncoderNotSet)
+	}
+}
+
+func TestFileEncoderDecode(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "plain.txt", content: "aGVsbG8="},
+		{name: "datauri.txt", content: "data:text/plain;base64,aGVsbG8=\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fpath := filepath.Join(dir, tt.name)
+			if err := os.WriteFile(fpath, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := NewFileEncoder(fpath, WithFormatEncoder(NewBase64Encoder(""))).Decode()
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if string(got) != "hello" {
+				t.Errorf("Decode() = %q, want %q", got, "hello")
+			}
+		})
+	}
+}
+
+func TestFileEncoderEncodeTo(t *testing.T) {
+	// PNG signature followed by enough data to span several encoder blocks
+	content := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0x00, 0x01, 0xfe, 0xff, 0x42}, 10000)...)
+	fpath := filepath.Join(t.TempDir(), "image.png")
+	if err := os.WriteFile(fpath, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	encoders := map[string]FormatEncoder{
+		"base64": NewBase64Encoder(""),
+		"base32": NewBase32Encoder(""),
+		"hex":    NewHexEncoder(),
+		"gob":    NewGobEncoder(),
+	}
+	for name, formatEncoder := range encoders {
+		t.Run(name, func(t *testing.T) {
+			for _, withMimeType := range []bool{false, true} {
+				enc := NewFileEncoder(fpath, WithFormatEncoder(formatEncoder), WithMimeType(withMimeType))
+
+				want, err := enc.Encode()
+				if withMimeType && name != "base64" {
+					if !errors.Is(err, ErrDataURINotSupported) {
+						t.Errorf("Encode() error = %v, want %v", err, ErrDataURINotSupported)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("Encode() error = %v", err)
+				}
+
+				var got bytes.Buffer
+				if err := enc.EncodeTo(&got); err != nil {
+					t.Fatalf("EncodeTo() error = %v", err)
+				}
+				if got.String() != want {
+					t.Errorf("EncodeTo() differs from Encode() with mime type %v", withMimeType)
+				}
+			}
+		})
+	}
+}
+
+func TestTextEncoderCompression(t *testing.T) {
+	text := strings.Repeat(`{"name":"helpme","tags":["encode","compress"]},`, 50)
+	for _, name := range Compressions() {
+		t.Run(name, func(t *testing.T) {
+			encoded, err := NewTextEncoder(text, WithFormatEncoder(NewBase64Encoder("")), WithCompression(name)).Encode()
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if plain := NewBase64Encoder("").EncodeToString([]byte(text)); len(encoded) >= len(plain) {
+				t.Errorf("Encode() = %d bytes, want less than uncompressed %d", len(encoded), len(plain))
+			}
+
+			// the algorithm is detected from the header, not the option
+			got, err := NewTextEncoder(encoded, WithFormatEncoder(NewBase64Encoder("")), WithCompression("gzip")).Decode()
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if string(got) != text {
+				t.Errorf("Decode() = %q, want %q", got, text)
+			}
+		})
+	}
+}
+
+func TestDecodeDetectsCompression(t *testing.T) {
+	text := strings.Repeat("compressed without asking ", 20)
+	for _, name := range Compressions() {
+		encoded, err := NewTextEncoder(text, WithFormatEncoder(NewBase64Encoder("")), WithCompression(name)).Encode()
+		if err != nil {
+			t.Fatalf("%s: Encode() error = %v", name, err)
+		}
+
+		got, err := NewTextEncoder(encoded, WithFormatEncoder(NewBase64Encoder(""))).Decode()
+		if err != nil || string(got) != text {
+			t.Errorf("%s: Decode() without WithCompression = %q, %v, want %q", name, got, err, text)
+		}
+	}
+
+	// a gzip file encoded as is must come back as the gzip file
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	w.Write([]byte(text))
+	w.Close()
+	encoded := NewBase64Encoder("").EncodeToString(gz.Bytes())
+	got, err := NewTextEncoder(encoded, WithFormatEncoder(NewBase64Encoder(""))).Decode()
+	if err != nil || !bytes.Equal(got, gz.Bytes()) {
+		t.Errorf("Decode() of a gzip file = %x, %v, want it unchanged", got, err)
+	}
+
+	// plain data that happens to start like a compressed payload is left alone
+	for _, c := range compressions {
+		plain := append(append([]byte{}, c.header...), gz.Bytes()[:16]...)
+		encoded := NewBase64Encoder("").EncodeToString(plain)
+		got, err := NewTextEncoder(encoded, WithFormatEncoder(NewBase64Encoder(""))).Decode()
+		if err != nil || !bytes.Equal(got, plain) {
+			t.Errorf("%s: Decode() of plain data with a stage header = %q, %v, want it unchanged", c.name, got, err)
+		}
+	}
+}
+
+func TestCompressionErrors(t *testing.T) {
+	_, err := NewTextEncoder("hello", WithFormatEncoder(NewHexEncoder()), WithCompression("brotli")).Encode()
+	if !errors.Is(err, ErrUnknownCompression) {
+		t.Errorf("Encode() error = %v, want %v", err, ErrUnknownCompression)
+	}
+
+	encoded := NewHexEncoder().EncodeToString([]byte("hello"))
+	_, err = NewTextEncoder(encoded, WithFormatEncoder(NewHexEncoder()), WithCompression("zlib")).Decode()
+	if !errors.Is(err, ErrCompressionNotDetected) {
+		t.Errorf("Decode() error = %v, want %v", err, ErrCompressionNotDetected)
+	}
+}
+
+func TestFileEncoderCompression(t *testing.T) {
+	content := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte("IDAT chunk "), 5000)...)
+	dir := t.TempDir()
+	fpath := filepath.Join(dir, "image.png")
+	if err := os.WriteFile(fpath, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	encoders := map[string]FormatEncoder{
+		"base64": NewBase64Encoder(""),
+		"gob":    NewGobEncoder(),
+	}
+	for name, formatEncoder := range encoders {
+		for _, compression := range Compressions() {
+			t.Run(name+"/"+compression, func(t *testing.T) {
+				enc := NewFileEncoder(fpath, WithFormatEncoder(formatEncoder), WithCompression(compression))
+
+				var encoded bytes.Buffer
+				if err := enc.EncodeTo(&encoded); err != nil {
+					t.Fatalf("EncodeTo() error = %v", err)
+				}
+				if want, _ := enc.Encode(); encoded.String() != want {
+					t.Errorf("EncodeTo() differs from Encode()")
+				}
+
+				out := filepath.Join(dir, name+"."+compression)
+				if err := os.WriteFile(out, encoded.Bytes(), 0644); err != nil {
+					t.Fatal(err)
+				}
+				got, err := NewFileEncoder(out, WithFormatEncoder(formatEncoder), WithCompression(compression)).Decode()
+				if err != nil {
+					t.Fatalf("Decode() error = %v", err)
+				}
+				if !bytes.Equal(got, content) {
+					t.Errorf("Decode() returned %d bytes, want the original %d", len(got), len(content))
+				}
+			})
+		}
+	}
+}
+
+func TestTextEncoderEncryption(t *testing.T) {
+	const secret = "db password: hunter2"
+	for _, cipher := range Ciphers() {
+		t.Run(cipher, func(t *testing.T) {
+			opts := []EncoderOpt{WithFormatEncoder(NewBase64Encoder("")), WithEncryption("correct horse"), WithCipher(cipher), WithCompression("gzip")}
+			encoded, err := NewTextEncoder(secret, opts...).Encode()
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if again, _ := NewTextEncoder(secret, opts...).Encode(); again == encoded {
+				t.Errorf("Encode() returned the same envelope twice, want a fresh salt and nonce")
+			}
+
+			got, err := NewTextEncoder(encoded, opts...).Decode()
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if string(got) != secret {
+				t.Errorf("Decode() = %q, want %q", got, secret)
+			}
+
+			_, err = NewTextEncoder(encoded, WithFormatEncoder(NewBase64Encoder("")), WithEncryption("wrong horse")).Decode()
+			if !errors.Is(err, ErrDecryptionFailed) {
+				t.Errorf("Decode() with wrong passphrase error = %v, want %v", err, ErrDecryptionFailed)
+			}
+
+			raw, _ := NewBase64Encoder("").DecodeString(encoded)
+			raw[len(raw)-1] ^= 0x01
+			tampered := NewBase64Encoder("").EncodeToString(raw)
+			_, err = NewTextEncoder(tampered, opts...).Decode()
+			if !errors.Is(err, ErrDecryptionFailed) {
+				t.Errorf("Decode() of tampered data error = %v, want %v", err, ErrDecryptionFailed)
+			}
+		})
+	}
+}
+
+func TestEncryptionErrors(t *testing.T) {
+	_, err := NewTextEncoder("hello", WithFormatEncoder(NewHexEncoder()), WithEncryption("pw"), WithCipher("rot13")).Encode()
+	if !errors.Is(err, ErrUnknownCipher) {
+		t.Errorf("Encode() error = %v, want %v", err, ErrUnknownCipher)
+	}
+
+	encoded := NewHexEncoder().EncodeToString([]byte("not sealed"))
+	_, err = NewTextEncoder(encoded, WithFormatEncoder(NewHexEncoder()), WithEncryption("pw")).Decode()
+	if !errors.Is(err, ErrInvalidEnvelope) {
+		t.Errorf("Decode() error = %v, want %v", err, ErrInvalidEnvelope)
+	}
+
+	encoded = NewHexEncoder().EncodeToString([]byte("hme\x09\x01\x01"))
+	_, err = NewTextEncoder(encoded, WithFormatEncoder(NewHexEncoder()), WithEncryption("pw")).Decode()
+	if !errors.Is(err, ErrUnsupportedEnvelope) {
+		t.Errorf("Decode() error = %v, want %v", err, ErrUnsupportedEnvelope)
+	}
+}
+
+func TestFileEncoderEncryption(t *testing.T) {
+	content := []byte("%PDF-1.7\n" + strings.Repeat("confidential ", 1000))
+	dir := t.TempDir()
+	fpath := filepath.Join(dir, "report.pdf")
+	if err := os.WriteFile(fpath, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := []EncoderOpt{WithFormatEncoder(NewBase64Encoder("")), WithEncryption("s3cret"), WithMimeType(true)}
+	var encoded bytes.Buffer
+	if err := NewFileEncoder(fpath, opts...).EncodeTo(&encoded); err != nil {
+		t.Fatalf("EncodeTo() error = %v", err)
+	}
+	if !strings.HasPrefix(encoded.String(), "data:application/octet-stream;") {
+		t.Errorf("EncodeTo() = %q, want an octet-stream data URI", encoded.String()[:40])
+	}
+
+	out := filepath.Join(dir, "report.enc")
+	if err := os.WriteFile(out, encoded.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := NewFileEncoder(out, opts...).Decode()
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("Decode() returned %d bytes, want the original %d", len(got), len(content))
+	}
+}
+
+func TestCopyToClipboard(t *testing.T) {
+	cb := clipboard.NewMemory()
+	ctx := context.Background()
+
+	encoded, err := NewTextEncoder("hello", WithFormatEncoder(NewHexEncoder()), WithCopyToClipboard(true), WithClipboard(cb)).Encode()
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if got, _ := cb.Read(ctx, clipboard.FormatText); string(got) != encoded {
+		t.Errorf("clipboard = %q, want %q", got, encoded)
+	}
+
+	encoded, err = NewFileEncoder("../../test_data/portrait.gif", WithFormatEncoder(NewBase64Encoder("")), WithCopyToClipboard(true), WithClipboard(cb)).Encode()
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if got, _ := cb.Read(ctx, clipboard.FormatText); string(got) != encoded {
+		t.Errorf("clipboard holds %d bytes, want the %d encoded", len(got), len(encoded))
+	}
+}
@@ -0,0 +1,172 @@
+package encode
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// Encrypted data is sealed in a versioned envelope:
+//
+//	magic   "hme"
+//	version 1 byte
+//	cipher  1 byte, see ciphers
+//	kdf     1 byte, see kdfArgon2id
+//	salt    16 bytes
+//	nonce   cipher nonce size
+//	sealed  ciphertext followed by the authentication tag
+//
+// Everything before the sealed data is authenticated as additional data.
+const (
+	envelopeMagic   = "hme"
+	envelopeVersion = 1
+	saltSize        = 16
+	keySize         = 32
+)
+
+// kdfArgon2id is the only key derivation so far, the envelope leaves room for more
+const kdfArgon2id byte = 1
+
+// encryption holds the WithEncryption settings of an encoder
+type encryption struct {
+	passphrase string
+	cipher     string
+}
+
+func (e encryption) enabled() bool {
+	return e.passphrase != ""
+}
+
+type cipherSpec struct {
+	id   byte
+	name string
+	aead func(key []byte) (cipher.AEAD, error)
+}
+
+var ciphers = []cipherSpec{
+	{id: 1, name: "aes-gcm", aead: func(key []byte) (cipher.AEAD, error) {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		return cipher.NewGCM(block)
+	}},
+	{id: 2, name: "chacha20-poly1305", aead: chacha20poly1305.New},
+}
+
+// Ciphers returns the names WithCipher accepts
+func Ciphers() []string {
+	names := make([]string, 0, len(ciphers))
+	for _, c := range ciphers {
+		names = append(names, c.name)
+	}
+	return names
+}
+
+func lookupCipher(name string) (cipherSpec, error) {
+	if name == "" {
+		return ciphers[0], nil
+	}
+	for _, c := range ciphers {
+		if strings.EqualFold(c.name, name) {
+			return c, nil
+		}
+	}
+	return cipherSpec{}, fmt.Errorf("%w: %q", ErrUnknownCipher, name)
+}
+
+// deriveKey stretches passphrase into a cipher key with the given kdf,
+// the parameters of each kdf are fixed by the envelope version.
+func deriveKey(kdf byte, passphrase string, salt []byte) ([]byte, error) {
+	switch kdf {
+	case kdfArgon2id:
+		return argon2.IDKey([]byte(passphrase), salt, 1, 64*1024, 4, keySize), nil
+	default:
+		return nil, fmt.Errorf("%w: unknown key derivation %d", ErrInvalidEnvelope, kdf)
+	}
+}
+
+// encrypt seals src into a new envelope
+func (e encryption) encrypt(src []byte) ([]byte, error) {
+	spec, err := lookupCipher(e.cipher)
+	if err != nil {
+		return nil, err
+	}
+
+	header := []byte{envelopeMagic[0], envelopeMagic[1], envelopeMagic[2], envelopeVersion, spec.id, kdfArgon2id}
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	key, err := deriveKey(kdfArgon2id, e.passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := spec.aead(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	header = append(append(header, salt...), nonce...)
+	return aead.Seal(header, nonce, src, header), nil
+}
+
+// decrypt opens an envelope produced by encrypt, the cipher and kdf are
+// taken from the envelope rather than the encoder settings.
+func (e encryption) decrypt(src []byte) ([]byte, error) {
+	const fixed = len(envelopeMagic) + 3
+	if len(src) < fixed || string(src[:len(envelopeMagic)]) != envelopeMagic {
+		return nil, ErrInvalidEnvelope
+	}
+
+	version, cipherID, kdf := src[3], src[4], src[5]
+	if version != envelopeVersion {
+		return nil, fmt.Errorf("%w: version %d", ErrUnsupportedEnvelope, version)
+	}
+
+	var spec *cipherSpec
+	for i := range ciphers {
+		if ciphers[i].id == cipherID {
+			spec = &ciphers[i]
+		}
+	}
+	if spec == nil {
+		return nil, fmt.Errorf("%w: unknown cipher %d", ErrInvalidEnvelope, cipherID)
+	}
+
+	if len(src) < fixed+saltSize {
+		return nil, ErrInvalidEnvelope
+	}
+	key, err := deriveKey(kdf, e.passphrase, src[fixed:fixed+saltSize])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := spec.aead(key)
+	if err != nil {
+		return nil, err
+	}
+
+	headerSize := fixed + saltSize + aead.NonceSize()
+	if len(src) < headerSize+aead.Overhead() {
+		return nil, ErrInvalidEnvelope
+	}
+
+	header := src[:headerSize]
+	plain, err := aead.Open(nil, header[fixed+saltSize:], src[headerSize:], header)
+	if err != nil {
+		// a wrong key and modified data are indistinguishable to an AEAD
+		return nil, ErrDecryptionFailed
+	}
+	return plain, nil
+}
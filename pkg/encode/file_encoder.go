@@ -2,34 +2,44 @@ package encode
 
 import (
 	"fmt"
-	"net/http"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
-	"golang.design/x/clipboard"
+	"github.com/vldcreation/helpme-package/pkg/clipboard"
 )
 
 var (
-	// Mapping of allowed MIME types to their corresponding file extensions
+	// Mapping of allowed MIME types to their corresponding file extensions,
+	// it backs DefaultMimePolicy and extends the extension cross-check.
 	AllowedMimeTypes = map[string][]string{
 		"image/png":       {".png"},
 		"image/jpeg":      {".jpg", ".jpeg"},
+		"image/gif":       {".gif"},
 		"application/pdf": {".pdf"},
 		"text/plain":      {".go"}, // Assuming .go files are treated as plain text
 	}
 
-	AvailableFormatEncoder = []string{
-		"base64",
-		"base32",
-		"gob",
-		"hex"}
+	// AvailableFormatEncoder lists the built-in encoders Lookup accepts,
+	// encoders registered later are not added.
+	//
+	// Deprecated: use Names, which reads the registry under its lock.
+	AvailableFormatEncoder []string
 )
 
 type FileEncoder struct {
 	fpath           string
 	copyToClipboard bool
+	clipboard       clipboard.Clipboard
+	clipboardMode   ClipboardMode
 	withMimeType    bool
+	mimePolicy      *MimePolicy
 	formatEncoder   FormatEncoder
+	compression     string
+	encryption      encryption
+	digest          string
+	hmacKey         []byte
 }
 
 func NewFileEncoder(fpath string, opts ...EncoderOpt) Encoder {
@@ -53,22 +63,57 @@ func (i *FileEncoder) Encode() (string, error) {
 		return "", ErrFilePathNotSet
 	}
 
-	if i.formatEncoder == nil {
+	// a digest is hex formatted without a format encoder
+	if i.formatEncoder == nil && i.digest == "" {
 		return "", ErrEncoderNotSet
 	}
-	return i.encode()
+
+	encoded, err := i.encode()
+	if err != nil {
+		return "", wrapOpError(err, "encode", i.fpath, i.formatEncoder)
+	}
+	return encoded, nil
+}
+
+// EncodeTo streams the encoded file into w without holding the file in memory.
+// WithCopyToClipboard is ignored, use Encode to get the result as a whole.
+func (i *FileEncoder) EncodeTo(w io.Writer) error {
+	if i.fpath == "" {
+		return ErrFilePathNotSet
+	}
+
+	if i.formatEncoder == nil && i.digest == "" {
+		return ErrEncoderNotSet
+	}
+	return wrapOpError(i.encodeTo(w), "encode", i.fpath, i.formatEncoder)
 }
 
 func (i *FileEncoder) encode() (string, error) {
+	var res strings.Builder
+	if err := i.encodeTo(&res); err != nil {
+		return "", err
+	}
+
+	if i.copyToClipboard {
+		err := i.copyToClipboardAs(i.clipboardMode, res.String())
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return res.String(), nil
+}
+
+func (i *FileEncoder) encodeTo(w io.Writer) error {
 	path := filepath.Clean(i.fpath)
 
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return "", fmt.Errorf("image does not exist")
+	if _, err := os.Stat(path); err != nil {
+		return &OpError{Kind: ErrInvalidFilePath, Err: err}
 	}
 
 	file, err := os.Open(path)
 	if err != nil {
-		return "", err
+		return &OpError{Kind: ErrInvalidFile, Err: err}
 	}
 
 	defer func() {
@@ -78,61 +123,182 @@ func (i *FileEncoder) encode() (string, error) {
 		}
 	}()
 
-	buffer := make([]byte, 512)
-	if _, err := file.Read(buffer); err != nil {
-		return "", err
+	info, err := file.Stat()
+	if err != nil {
+		return &OpError{Kind: ErrInvalidFile, Err: err}
 	}
 
-	mimeType := http.DetectContentType(buffer)
+	_, err = i.encodeContent(w, file, file.Name(), info.Size())
+	return err
+}
 
-	// Validate the detected MIME type against the whitelist
-	if !isMimeTypeAllowed(mimeType) {
-		return "", fmt.Errorf("detected MIME type %s is not allowed", mimeType)
-	}
+// fileContent is an open file, or its content already read into memory
+type fileContent interface {
+	io.Reader
+	io.ReaderAt
+}
 
-	// Reset file pointer to the beginning to read entire content
-	if _, err := file.Seek(0, 0); err != nil {
-		return "", err
+// encodeContent encodes the content of the file name into w and returns the
+// MIME type sniffed from the content, before any stage changed it.
+func (i *FileEncoder) encodeContent(w io.Writer, file fileContent, name string, size int64) (string, error) {
+	// the digest covers any file, the MIME policy guards encoded content only
+	if i.digest != "" {
+		mimeType, err := DetectMimeType(file, size)
+		if err != nil {
+			return "", &OpError{Kind: ErrInvalidFile, Err: err}
+		}
+		hasher := &Hasher{digest: i.digest, hmacKey: i.hmacKey, formatEncoder: i.formatEncoder}
+		sum, err := hasher.Sum(file)
+		if err != nil {
+			return "", err
+		}
+		_, err = io.WriteString(w, sum)
+		return mimeType, err
 	}
 
-	// Read the entire file into a new buffer
-	fullBuffer, err := os.ReadFile(path)
+	mimeType, err := i.detectMimeType(file, name, size)
 	if err != nil {
 		return "", err
 	}
+	payloadMimeType := mimeType
 
-	res := i.formatEncoder.EncodeToString(fullBuffer)
+	var c *compression
+	if i.compression != "" {
+		if c, err = lookupCompression(i.compression); err != nil {
+			return "", err
+		}
+		// the payload is no longer the file itself
+		payloadMimeType = "application/octet-stream"
+	}
+	if i.encryption.enabled() {
+		payloadMimeType = "application/octet-stream"
+	}
 
 	if i.withMimeType {
-		res = fmt.Sprintf("data:%s;base64,%s", mimeType, res)
+		prefix, err := dataURIPrefix(payloadMimeType, i.formatEncoder)
+		if err != nil {
+			return "", &OpError{MimeType: payloadMimeType, Err: err}
+		}
+		if _, err := io.WriteString(w, prefix); err != nil {
+			return "", err
+		}
 	}
 
-	if i.copyToClipboard {
-		err = i.copyFileToCliboard(res)
+	stream, ok := i.formatEncoder.(StreamEncoder)
+	if !ok || i.encryption.enabled() {
+		// the format can only encode a whole buffer, or encryption seals the whole file at once
+		fullBuffer, err := io.ReadAll(file)
 		if err != nil {
 			return "", err
 		}
+		if fullBuffer, err = sealStages(fullBuffer, i.compression, i.encryption); err != nil {
+			return "", err
+		}
+		_, err = io.WriteString(w, i.formatEncoder.EncodeToString(fullBuffer))
+		return mimeType, err
+	}
+
+	enc := stream.NewEncoder(w)
+	var dst io.WriteCloser = enc
+	if c != nil {
+		if dst, err = c.compressWriter(enc); err != nil {
+			enc.Close()
+			return "", err
+		}
+	}
+
+	if _, err := io.Copy(dst, file); err != nil {
+		if c != nil {
+			dst.Close()
+		}
+		enc.Close()
+		return "", err
+	}
+
+	// flush the compressed stream before any partially encoded block
+	if c != nil {
+		if err := dst.Close(); err != nil {
+			enc.Close()
+			return "", err
+		}
+	}
+	return mimeType, enc.Close()
+}
+
+// Decode reads the encoded content of the file and decodes it, data URIs
+// are decoded as their header says.
+func (i *FileEncoder) Decode() ([]byte, error) {
+	if i.fpath == "" {
+		return nil, ErrFilePathNotSet
+	}
+
+	b, err := os.ReadFile(filepath.Clean(i.fpath))
+	if err != nil {
+		return nil, wrapOpError(&OpError{Kind: ErrInvalidFile, Err: err}, "decode", i.fpath, i.formatEncoder)
 	}
 
-	return res, nil
+	decoded, err := decodePayload(string(b), i.formatEncoder, i.compression, i.encryption)
+	if err != nil {
+		return nil, wrapOpError(err, "decode", i.fpath, i.formatEncoder)
+	}
+	return decoded, nil
 }
 
-func isMimeTypeAllowed(mimeType string) bool {
-	_, exists := AllowedMimeTypes[mimeType]
-	return exists
+// decodePayload decodes encoded text produced by an encoder with the given
+// format and stages, data URIs are decoded as their header says.
+func decodePayload(encoded string, formatEncoder FormatEncoder, compression string, enc encryption) ([]byte, error) {
+	// a data URI says how it is encoded, the format encoder is not needed
+	encoded = strings.TrimSpace(encoded)
+	if strings.HasPrefix(encoded, "data:") {
+		uri, err := ParseDataURI(encoded)
+		if err != nil {
+			return nil, err
+		}
+		return openStages(uri.Data, compression, enc)
+	}
+
+	if formatEncoder == nil {
+		return nil, ErrEncoderNotSet
+	}
+
+	decoder, ok := formatEncoder.(FormatDecoder)
+	if !ok {
+		return nil, ErrDecoderNotSupported
+	}
+
+	decoded, err := decoder.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	return openStages(decoded, compression, enc)
 }
 
-func (i *FileEncoder) copyFileToCliboard(text string) error {
-	err := clipboard.Init()
+// detectMimeType sniffs the content of the file name and validates the
+// result against the extension and the MIME policy, the content is read
+// through ReadAt only. Failures are returned as an *OpError for the caller
+// to complete.
+func (i *FileEncoder) detectMimeType(file io.ReaderAt, name string, size int64) (string, error) {
+	if size == 0 {
+		return "", &OpError{Kind: ErrInvalidFile, Err: io.EOF}
+	}
+
+	mimeType, err := DetectMimeType(file, size)
 	if err != nil {
-		return err
+		return "", &OpError{Kind: ErrInvalidFile, Err: err}
+	}
+
+	if !matchesExtension(name, mimeType) {
+		return "", &OpError{Kind: ErrInvalidExtension, MimeType: mimeType, Err: ErrMimeTypeMismatch}
 	}
 
-	changed := clipboard.Write(clipboard.FmtText, []byte(text))
-	select {
-	case <-changed:
-		return fmt.Errorf(`"text data" is no longer available from clipboard.`)
-	default:
-		return nil
+	policy := DefaultMimePolicy()
+	if i.mimePolicy != nil {
+		policy = *i.mimePolicy
 	}
+	if !policy.Allowed(mimeType) {
+		return "", &OpError{Kind: ErrMimeTypeNotAllowed, MimeType: mimeType}
+	}
+
+	return mimeType, nil
 }
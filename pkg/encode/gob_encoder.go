@@ -3,35 +3,53 @@ package encode
 import (
 	"bytes"
 	"encoding/gob"
+	"fmt"
 )
 
-type GobEncoder struct {
-	buf *bytes.Buffer
-	enc *gob.Encoder
-	dec *gob.Decoder
-}
+// GobEncoder wraps the source bytes in a gob stream. Every call starts a
+// fresh stream so the output does not depend on previous calls.
+// Use NewValueEncoder with the gob codec to serialize other Go values.
+type GobEncoder struct{}
 
-func NewGobEncoder() FormatEncoder {
-	b := bytes.NewBuffer(nil)
-	return &GobEncoder{
-		buf: b,
-		enc: gob.NewEncoder(b),
-		dec: gob.NewDecoder(b),
-	}
+func NewGobEncoder() FormatCodec {
+	return &GobEncoder{}
 }
 
-// WIP
-func (g *GobEncoder) Encode(src []byte, dst []byte) {
+// Encode copies the gob stream of src into dst, which must be large enough
+func (g *GobEncoder) Encode(dst []byte, src []byte) {
+	copy(dst, g.encode(src))
 }
 
 func (g *GobEncoder) EncodeToString(src []byte) string {
-	if err := g.enc.Encode(src); err != nil {
-		return ""
+	return string(g.encode(src))
+}
+
+func (g *GobEncoder) Decode(dst []byte, src []byte) (int, error) {
+	b, err := g.decode(src)
+	if err != nil {
+		return 0, err
+	}
+	if len(dst) < len(b) {
+		return 0, fmt.Errorf("gob: destination too short, need %d bytes", len(b))
 	}
+	return copy(dst, b), nil
+}
+
+func (g *GobEncoder) DecodeString(src string) ([]byte, error) {
+	return g.decode([]byte(src))
+}
 
-	return string(g.buf.Bytes())
+func (g *GobEncoder) encode(src []byte) []byte {
+	var buf bytes.Buffer
+	// encoding a []byte into a bytes.Buffer cannot fail
+	gob.NewEncoder(&buf).Encode(src)
+	return buf.Bytes()
 }
 
-func (g *GobEncoder) Decode(dst, src []byte) (int, error) {
-	return len(src), nil
+func (g *GobEncoder) decode(src []byte) ([]byte, error) {
+	var b []byte
+	if err := gob.NewDecoder(bytes.NewReader(src)).Decode(&b); err != nil {
+		return nil, err
+	}
+	return b, nil
 }
@@ -0,0 +1,296 @@
+package encode
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cespare/xxhash/v2"
+	"golang.org/x/crypto/blake2b"
+)
+
+// Verify statuses, named like sha256sum --check reports them
+const (
+	VerifyOK      = "OK"
+	VerifyFailed  = "FAILED"
+	VerifyMissing = "MISSING"
+)
+
+type digestSpec struct {
+	newHash func() hash.Hash
+	// mac is false for checksums, which cannot take a key
+	mac bool
+	// newKeyed replaces HMAC for hashes with a keyed mode of their own
+	newKeyed func(key []byte) (hash.Hash, error)
+}
+
+func blake2bSpec(size int) digestSpec {
+	return digestSpec{
+		newHash: func() hash.Hash {
+			// a nil key never fails
+			h, _ := blake2b.New(size, nil)
+			return h
+		},
+		mac: true,
+		newKeyed: func(key []byte) (hash.Hash, error) {
+			return blake2b.New(size, key)
+		},
+	}
+}
+
+var digests = map[string]digestSpec{
+	"md5":         {newHash: md5.New, mac: true},
+	"sha1":        {newHash: sha1.New, mac: true},
+	"sha224":      {newHash: sha256.New224, mac: true},
+	"sha256":      {newHash: sha256.New, mac: true},
+	"sha384":      {newHash: sha512.New384, mac: true},
+	"sha512":      {newHash: sha512.New, mac: true},
+	"blake2b":     blake2bSpec(blake2b.Size),
+	"blake2b-256": blake2bSpec(blake2b.Size256),
+	"blake2b-512": blake2bSpec(blake2b.Size),
+	"crc32":       {newHash: func() hash.Hash { return crc32.NewIEEE() }},
+	"crc32c":      {newHash: func() hash.Hash { return crc32.New(crc32.MakeTable(crc32.Castagnoli)) }},
+	"xxhash":      {newHash: func() hash.Hash { return xxhash.New() }},
+}
+
+// Digests returns the names WithDigest accepts
+func Digests() []string {
+	names := make([]string, 0, len(digests))
+	for name := range digests {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Hasher computes digests of text and files and formats them with a
+// FormatEncoder, hex unless WithFormatEncoder says otherwise.
+type Hasher struct {
+	digest        string
+	hmacKey       []byte
+	formatEncoder FormatEncoder
+}
+
+// NewHasher returns a sha256 hasher unless opts pick another digest
+func NewHasher(opts ...EncoderOpt) *Hasher {
+	h := &Hasher{digest: "sha256"}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+func (h *Hasher) ApplyOpt(opts ...EncoderOpt) {
+	for _, opt := range opts {
+		opt(h)
+	}
+}
+
+// Sum digests everything read from r
+func (h *Hasher) Sum(r io.Reader) (string, error) {
+	sum, err := h.sum(r)
+	if err != nil {
+		return "", err
+	}
+	return h.format().EncodeToString(sum), nil
+}
+
+func (h *Hasher) SumString(s string) (string, error) {
+	return h.Sum(strings.NewReader(s))
+}
+
+// SumFile streams the file at path through the digest
+func (h *Hasher) SumFile(path string) (string, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return "", &OpError{Op: "hash", Path: path, Kind: ErrInvalidFile, Err: err}
+	}
+	defer f.Close()
+
+	sum, err := h.Sum(f)
+	if err != nil {
+		return "", &OpError{Op: "hash", Path: path, Err: err}
+	}
+	return sum, nil
+}
+
+// WriteSums writes a line per path in the "<hex digest>  <path>" format of
+// sha256sum and friends, which Verify reads back.
+func (h *Hasher) WriteSums(w io.Writer, paths ...string) error {
+	for _, path := range paths {
+		f, err := os.Open(filepath.Clean(path))
+		if err != nil {
+			return &OpError{Op: "hash", Path: path, Kind: ErrInvalidFile, Err: err}
+		}
+		sum, err := h.sum(f)
+		f.Close()
+		if err != nil {
+			return &OpError{Op: "hash", Path: path, Err: err}
+		}
+
+		if _, err := fmt.Fprintf(w, "%s  %s\n", hex.EncodeToString(sum), filepath.ToSlash(path)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// VerifyResult is the outcome of one line of a checksum file
+type VerifyResult struct {
+	Path   string
+	Status string
+	Err    error
+}
+
+// Verify checks the files listed in a sha256sum style checksum file, in the
+// GNU "<hex>  <path>" or the BSD "SHA256 (<path>) = <hex>" format. Relative
+// paths are resolved against dir. A BSD line naming another algorithm than
+// the Hasher fails with ErrDigestMismatch in its result. It returns
+// ErrChecksumMismatch when any file failed or is missing, along with the
+// result of every line.
+func (h *Hasher) Verify(r io.Reader, dir string) ([]VerifyResult, error) {
+	var (
+		results []VerifyResult
+		failed  int
+		// the first line checked with the wrong digest explains the failure
+		digestErr error
+	)
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		algorithm, want, path, ok := parseSumLine(line)
+		if !ok {
+			return results, fmt.Errorf("%w: line %d is not a checksum line", ErrInvalidChecksumFile, n)
+		}
+
+		res := VerifyResult{Path: path, Status: VerifyOK}
+		target := path
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(dir, filepath.FromSlash(path))
+		}
+
+		if algorithm != "" && digestName(algorithm) != digestName(h.digest) {
+			// hashing with the wrong algorithm would only report a bogus FAILED
+			res.Status = VerifyFailed
+			res.Err = fmt.Errorf("%w: line %d is a %s checksum, verifying with %s", ErrDigestMismatch, n, algorithm, h.digest)
+			if digestErr == nil {
+				digestErr = res.Err
+			}
+		} else if f, err := os.Open(target); err != nil {
+			res.Status, res.Err = VerifyMissing, err
+		} else {
+			sum, err := h.sum(f)
+			f.Close()
+			switch {
+			case err != nil:
+				res.Status, res.Err = VerifyFailed, err
+			case !strings.EqualFold(hex.EncodeToString(sum), want):
+				res.Status = VerifyFailed
+			}
+		}
+
+		if res.Status != VerifyOK {
+			failed++
+		}
+		results = append(results, res)
+	}
+	if err := scanner.Err(); err != nil {
+		return results, err
+	}
+
+	if failed > 0 {
+		return results, errors.Join(fmt.Errorf("%w: %d of %d files", ErrChecksumMismatch, failed, len(results)), digestErr)
+	}
+	return results, nil
+}
+
+// parseSumLine splits a GNU or BSD checksum line into algorithm, digest and
+// path. GNU lines do not name their algorithm, it is left empty.
+func parseSumLine(line string) (string, string, string, bool) {
+	// BSD: SHA256 (path) = digest
+	if open := strings.Index(line, " ("); open > 0 {
+		if end := strings.LastIndex(line, ") = "); end > open {
+			digest := line[end+4:]
+			return line[:open], digest, line[open+2 : end], isHexDigest(digest)
+		}
+	}
+
+	// GNU: digest, a space, then a space for text or '*' for binary mode
+	digest, path, ok := strings.Cut(line, " ")
+	if !ok || path == "" || !isHexDigest(digest) {
+		return "", "", "", false
+	}
+	if path[0] == ' ' || path[0] == '*' {
+		path = path[1:]
+	}
+	return "", digest, path, path != ""
+}
+
+// digestName normalizes a digest name, BSD lines spell it in upper case
+// and b2sum calls the full size BLAKE2b without a size suffix.
+func digestName(name string) string {
+	name = strings.ToLower(name)
+	if name == "blake2b-512" {
+		return "blake2b"
+	}
+	return name
+}
+
+func isHexDigest(s string) bool {
+	_, err := hex.DecodeString(s)
+	return s != "" && err == nil
+}
+
+func (h *Hasher) sum(r io.Reader) ([]byte, error) {
+	hh, err := h.newHash()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(hh, r); err != nil {
+		return nil, err
+	}
+	return hh.Sum(nil), nil
+}
+
+func (h *Hasher) newHash() (hash.Hash, error) {
+	spec, ok := digests[strings.ToLower(h.digest)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownDigest, h.digest)
+	}
+	if h.hmacKey == nil {
+		return spec.newHash(), nil
+	}
+
+	if !spec.mac {
+		return nil, fmt.Errorf("%w: %s is a checksum and cannot take a key", ErrInvalidDigestOption, h.digest)
+	}
+	if spec.newKeyed != nil {
+		return spec.newKeyed(h.hmacKey)
+	}
+	return hmac.New(spec.newHash, h.hmacKey), nil
+}
+
+func (h *Hasher) format() FormatEncoder {
+	if h.formatEncoder == nil {
+		return NewHexEncoder()
+	}
+	return h.formatEncoder
+}
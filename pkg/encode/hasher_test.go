@@ -0,0 +1,128 @@
+package encode
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHasherSum(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []EncoderOpt
+		src  string
+		want string
+	}{
+		{name: "default sha256", src: "abc", want: "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{name: "md5", opts: []EncoderOpt{WithDigest("md5")}, src: "abc", want: "900150983cd24fb0d6963f7d28e17f72"},
+		{name: "sha1", opts: []EncoderOpt{WithDigest("SHA1")}, src: "abc", want: "a9993e364706816aba3e25717850c26c9cd0d89d"},
+		{name: "sha512", opts: []EncoderOpt{WithDigest("sha512")}, src: "abc", want: "ddaf35a193617abacc417349ae20413112e6fa4e89a97ea20a9eeee64b55d39a2192992a274fc1a836ba3c23a3feebbd454d4423643ce80e2a9ac94fa54ca49f"},
+		{name: "blake2b-256", opts: []EncoderOpt{WithDigest("blake2b-256")}, src: "abc", want: "bddd813c634239723171ef3fee98579b94964e3bb1cb3e427262c8c068d52319"},
+		{name: "crc32", opts: []EncoderOpt{WithDigest("crc32")}, src: "abc", want: "352441c2"},
+		{name: "xxhash", opts: []EncoderOpt{WithDigest("xxhash")}, src: "abc", want: "44bc2cf5ad770999"},
+		{name: "hmac sha256", opts: []EncoderOpt{WithHMACKey([]byte("key"))}, src: "The quick brown fox jumps over the lazy dog", want: "f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8"},
+		{name: "keyed blake2b", opts: []EncoderOpt{WithDigest("blake2b-256"), WithHMACKey([]byte("key"))}, src: "abc", want: "0330531d097355a3f72e80d55c1245ccf79f1704431c6e3887938320442c23c0"},
+		{name: "base64 output", opts: []EncoderOpt{WithDigest("md5"), WithFormatEncoder(NewBase64Encoder(""))}, src: "abc", want: "kAFQmDzST7DWlj99KOF/cg=="},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewHasher(tt.opts...).SumString(tt.src)
+			if err != nil {
+				t.Fatalf("SumString() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("SumString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasherErrors(t *testing.T) {
+	if _, err := NewHasher(WithDigest("sha3")).SumString("abc"); !errors.Is(err, ErrUnknownDigest) {
+		t.Errorf("SumString() error = %v, want %v", err, ErrUnknownDigest)
+	}
+	if _, err := NewHasher(WithDigest("crc32"), WithHMACKey([]byte("key"))).SumString("abc"); !errors.Is(err, ErrInvalidDigestOption) {
+		t.Errorf("SumString() error = %v, want %v", err, ErrInvalidDigestOption)
+	}
+}
+
+func TestEncoderWithDigest(t *testing.T) {
+	got, err := NewTextEncoder("abc", WithDigest("sha1")).Encode()
+	if err != nil || got != "a9993e364706816aba3e25717850c26c9cd0d89d" {
+		t.Errorf("TextEncoder.Encode() = %q, %v", got, err)
+	}
+
+	// files of any type are digested, streamed through EncodeTo
+	fpath := filepath.Join(t.TempDir(), "data.bin")
+	if err := os.WriteFile(fpath, []byte("abc"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := NewFileEncoder(fpath, WithDigest("crc32")).EncodeTo(&buf); err != nil || buf.String() != "352441c2" {
+		t.Errorf("FileEncoder.EncodeTo() = %q, %v", buf.String(), err)
+	}
+}
+
+func TestHasherVerify(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{"a.txt": "alpha", "b.txt": "bravo", "sub/c.txt": "charlie"} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	h := NewHasher()
+	var sums bytes.Buffer
+	if err := h.WriteSums(&sums, filepath.Join(dir, "a.txt"), filepath.Join(dir, "sub", "c.txt")); err != nil {
+		t.Fatalf("WriteSums() error = %v", err)
+	}
+	if results, err := h.Verify(&sums, ""); err != nil || len(results) != 2 {
+		t.Fatalf("Verify() = %v, %v", results, err)
+	}
+
+	bravo, _ := h.SumString("bravo")
+	alpha, _ := h.SumString("alpha")
+	checksums := strings.Join([]string{
+		"# relative to dir",
+		alpha + "  a.txt",
+		alpha + " *b.txt",
+		"SHA256 (sub/c.txt) = " + bravo,
+		bravo + "  missing.txt",
+	}, "\n")
+
+	results, err := h.Verify(strings.NewReader(checksums), dir)
+	if !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("Verify() error = %v, want %v", err, ErrChecksumMismatch)
+	}
+	want := map[string]string{"a.txt": VerifyOK, "b.txt": VerifyFailed, "sub/c.txt": VerifyFailed, "missing.txt": VerifyMissing}
+	if len(results) != len(want) {
+		t.Fatalf("Verify() returned %d results, want %d", len(results), len(want))
+	}
+	for _, res := range results {
+		if res.Status != want[res.Path] {
+			t.Errorf("Verify() %s = %s, want %s", res.Path, res.Status, want[res.Path])
+		}
+	}
+
+	if _, err := h.Verify(strings.NewReader("not a checksum line"), dir); !errors.Is(err, ErrInvalidChecksumFile) {
+		t.Errorf("Verify() error = %v, want %v", err, ErrInvalidChecksumFile)
+	}
+
+	// a BSD line of another algorithm is not hashed with the Hasher digest
+	sha512, _ := NewHasher(WithDigest("sha512")).SumString("alpha")
+	results, err = h.Verify(strings.NewReader("SHA512 (a.txt) = "+sha512), dir)
+	if !errors.Is(err, ErrChecksumMismatch) || !errors.Is(err, ErrDigestMismatch) {
+		t.Errorf("Verify() of a SHA512 line error = %v, want %v and %v", err, ErrChecksumMismatch, ErrDigestMismatch)
+	}
+	if len(results) != 1 || results[0].Status != VerifyFailed || !errors.Is(results[0].Err, ErrDigestMismatch) {
+		t.Errorf("Verify() of a SHA512 line = %+v, want %s with %v", results, VerifyFailed, ErrDigestMismatch)
+	}
+	if results, err := NewHasher(WithDigest("sha512")).Verify(strings.NewReader("SHA512 (a.txt) = "+sha512), dir); err != nil {
+		t.Errorf("Verify() of a SHA512 line with a sha512 Hasher = %+v, %v", results, err)
+	}
+}
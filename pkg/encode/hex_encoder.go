@@ -1,9 +1,16 @@
 package encode
 
-import "encoding/hex"
+import (
+	"encoding/hex"
+	"io"
+)
 
 type HexEncoder struct{}
 
+func NewHexEncoder() FormatCodec {
+	return &HexEncoder{}
+}
+
 func (h *HexEncoder) Encode(dst []byte, src []byte) {
 	hex.Encode(dst, src)
 }
@@ -11,3 +18,24 @@ func (h *HexEncoder) Encode(dst []byte, src []byte) {
 func (h *HexEncoder) EncodeToString(src []byte) string {
 	return hex.EncodeToString(src)
 }
+
+func (h *HexEncoder) NewEncoder(w io.Writer) io.WriteCloser {
+	return nopCloser{hex.NewEncoder(w)}
+}
+
+func (h *HexEncoder) Decode(dst []byte, src []byte) (int, error) {
+	return hex.Decode(dst, src)
+}
+
+func (h *HexEncoder) DecodeString(src string) ([]byte, error) {
+	return hex.DecodeString(src)
+}
+
+// nopCloser adds a no-op Close to encoders without trailing state
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
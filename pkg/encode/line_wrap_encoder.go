@@ -0,0 +1,107 @@
+package encode
+
+import (
+	"bytes"
+	"io"
+	"strings"
+)
+
+// LineWrapEncoder breaks the output of another format encoder into lines,
+// decoding drops the line breaks before decoding.
+type LineWrapEncoder struct {
+	enc   FormatEncoder
+	width int
+}
+
+func NewLineWrapEncoder(enc FormatEncoder, width int) FormatCodec {
+	return &LineWrapEncoder{enc: enc, width: width}
+}
+
+// Encode copies the wrapped encoding of src into dst, which must be large enough
+func (l *LineWrapEncoder) Encode(dst []byte, src []byte) {
+	copy(dst, l.EncodeToString(src))
+}
+
+func (l *LineWrapEncoder) EncodeToString(src []byte) string {
+	var b strings.Builder
+	w := &lineWriter{w: &b, width: l.width}
+	io.WriteString(w, l.enc.EncodeToString(src))
+	return b.String()
+}
+
+func (l *LineWrapEncoder) NewEncoder(w io.Writer) io.WriteCloser {
+	lw := &lineWriter{w: w, width: l.width}
+	if stream, ok := l.enc.(StreamEncoder); ok {
+		return stream.NewEncoder(lw)
+	}
+	return &bufferedEncoder{enc: l.enc, w: lw}
+}
+
+func (l *LineWrapEncoder) Decode(dst []byte, src []byte) (int, error) {
+	dec, ok := l.enc.(FormatDecoder)
+	if !ok {
+		return 0, ErrDecoderNotSupported
+	}
+	return dec.Decode(dst, []byte(stripLineBreaks(string(src))))
+}
+
+func (l *LineWrapEncoder) DecodeString(src string) ([]byte, error) {
+	dec, ok := l.enc.(FormatDecoder)
+	if !ok {
+		return nil, ErrDecoderNotSupported
+	}
+	return dec.DecodeString(stripLineBreaks(src))
+}
+
+func stripLineBreaks(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
+
+// lineWriter inserts a newline after every width bytes, a width of zero or less disables wrapping
+type lineWriter struct {
+	w      io.Writer
+	width  int
+	column int
+}
+
+func (l *lineWriter) Write(p []byte) (int, error) {
+	if l.width <= 0 {
+		return l.w.Write(p)
+	}
+
+	written := 0
+	for len(p) > 0 {
+		if l.column == l.width {
+			if _, err := l.w.Write([]byte{'\n'}); err != nil {
+				return written, err
+			}
+			l.column = 0
+		}
+
+		n := min(l.width-l.column, len(p))
+		if _, err := l.w.Write(p[:n]); err != nil {
+			return written, err
+		}
+		l.column += n
+		written += n
+		p = p[n:]
+	}
+	return written, nil
+}
+
+// bufferedEncoder collects everything and encodes it on Close, for formats
+// that can only encode a whole buffer
+type bufferedEncoder struct {
+	enc FormatEncoder
+	w   io.Writer
+	buf bytes.Buffer
+}
+
+func (b *bufferedEncoder) Write(p []byte) (int, error) {
+	return b.buf.Write(p)
+}
+
+func (b *bufferedEncoder) Close() error {
+	_, err := io.WriteString(b.w, b.enc.EncodeToString(b.buf.Bytes()))
+	return err
+}
@@ -0,0 +1,249 @@
+package encode
+
+import (
+	"archive/zip"
+	"io"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// sniffLen is how much of a file is inspected for a magic number
+const sniffLen = 512
+
+// MimePolicy decides which detected MIME types a FileEncoder accepts.
+// Patterns are full types such as "image/png" or wildcards such as
+// "image/*" and "*/*". An empty Allow list allows everything not denied,
+// Deny always wins over Allow.
+type MimePolicy struct {
+	Allow []string
+	Deny  []string
+}
+
+// DefaultMimePolicy is used by a FileEncoder without WithMimePolicy
+func DefaultMimePolicy() MimePolicy {
+	allow := make([]string, 0, len(AllowedMimeTypes))
+	for mimeType := range AllowedMimeTypes {
+		allow = append(allow, mimeType)
+	}
+	return MimePolicy{Allow: allow}
+}
+
+// Allowed reports whether mimeType passes the policy, parameters such as
+// "; charset=utf-8" are ignored.
+func (p MimePolicy) Allowed(mimeType string) bool {
+	mimeType = baseMimeType(mimeType)
+	for _, pattern := range p.Deny {
+		if matchMimeType(pattern, mimeType) {
+			return false
+		}
+	}
+
+	if len(p.Allow) == 0 {
+		return true
+	}
+	for _, pattern := range p.Allow {
+		if matchMimeType(pattern, mimeType) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchMimeType(pattern, mimeType string) bool {
+	pattern = baseMimeType(pattern)
+	if pattern == "*" || pattern == "*/*" {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+		typ, _, _ := strings.Cut(mimeType, "/")
+		return typ == prefix
+	}
+	return pattern == mimeType
+}
+
+func baseMimeType(mimeType string) string {
+	base, _, _ := strings.Cut(mimeType, ";")
+	return strings.ToLower(strings.TrimSpace(base))
+}
+
+// extensionMimeTypes lists the detected types a file extension may carry,
+// files with other extensions are not cross-checked.
+var extensionMimeTypes = map[string][]string{
+	".png":  {"image/png"},
+	".jpg":  {"image/jpeg"},
+	".jpeg": {"image/jpeg"},
+	".gif":  {"image/gif"},
+	".webp": {"image/webp"},
+	".avif": {"image/avif"},
+	".bmp":  {"image/bmp"},
+	".ico":  {"image/x-icon"},
+	".tif":  {"image/tiff"},
+	".tiff": {"image/tiff"},
+	".svg":  {"image/svg+xml"},
+	".pdf":  {"application/pdf"},
+	".zip": {
+		"application/zip",
+		"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+		"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		"application/vnd.openxmlformats-officedocument.presentationml.presentation",
+		"application/vnd.oasis.opendocument.text",
+		"application/vnd.oasis.opendocument.spreadsheet",
+		"application/vnd.oasis.opendocument.presentation",
+		"application/epub+zip",
+		"application/java-archive",
+	},
+	".gz":   {"application/x-gzip"},
+	".docx": {"application/vnd.openxmlformats-officedocument.wordprocessingml.document"},
+	".xlsx": {"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"},
+	".pptx": {"application/vnd.openxmlformats-officedocument.presentationml.presentation"},
+	".odt":  {"application/vnd.oasis.opendocument.text"},
+	".epub": {"application/epub+zip"},
+	".jar":  {"application/java-archive"},
+	".txt":  {"text/plain"},
+	".md":   {"text/plain"},
+	".csv":  {"text/plain"},
+	".json": {"text/plain"},
+	".yaml": {"text/plain"},
+	".yml":  {"text/plain"},
+	".html": {"text/html"},
+	".htm":  {"text/html"},
+	".xml":  {"text/xml"},
+	".mp3":  {"audio/mpeg"},
+	".mp4":  {"video/mp4"},
+}
+
+// signature is a magic number at the start of a file, '?' matches any byte
+type signature struct {
+	magic    string
+	mimeType string
+}
+
+func (s signature) match(head []byte) bool {
+	if len(head) < len(s.magic) {
+		return false
+	}
+	for i := 0; i < len(s.magic); i++ {
+		if s.magic[i] != '?' && s.magic[i] != head[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// signatures are checked before http.DetectContentType, which misses some of them
+var signatures = []signature{
+	{magic: "II*\x00", mimeType: "image/tiff"},
+	{magic: "MM\x00*", mimeType: "image/tiff"},
+	{magic: "????ftypavif", mimeType: "image/avif"},
+	{magic: "RIFF????WEBP", mimeType: "image/webp"},
+}
+
+// zipMimeTypes identifies zip based formats by an entry they always contain
+var zipMimeTypes = []struct {
+	prefix   string
+	mimeType string
+}{
+	{prefix: "word/", mimeType: "application/vnd.openxmlformats-officedocument.wordprocessingml.document"},
+	{prefix: "xl/", mimeType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"},
+	{prefix: "ppt/", mimeType: "application/vnd.openxmlformats-officedocument.presentationml.presentation"},
+	{prefix: "META-INF/MANIFEST.MF", mimeType: "application/java-archive"},
+}
+
+// DetectMimeType sniffs the content type of r, size bytes long, from its
+// magic number. Zip archives are opened to tell office documents apart.
+func DetectMimeType(r io.ReaderAt, size int64) (string, error) {
+	head := make([]byte, sniffLen)
+	n, err := r.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	head = head[:n]
+
+	for _, sig := range signatures {
+		if sig.match(head) {
+			return sig.mimeType, nil
+		}
+	}
+
+	mimeType := http.DetectContentType(head)
+	switch baseMimeType(mimeType) {
+	case "application/zip":
+		return zipMimeType(r, size), nil
+	case "text/xml", "text/plain":
+		if isSVG(head) {
+			return "image/svg+xml", nil
+		}
+	}
+	return mimeType, nil
+}
+
+// zipMimeType looks inside a zip archive for the format it packages
+func zipMimeType(r io.ReaderAt, size int64) string {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return "application/zip"
+	}
+
+	for _, f := range zr.File {
+		// ODF and EPUB store their type uncompressed in the first entry
+		if f.Name == "mimetype" && f.Method == zip.Store {
+			rc, err := f.Open()
+			if err != nil {
+				break
+			}
+			b, err := io.ReadAll(io.LimitReader(rc, 128))
+			rc.Close()
+			if err == nil {
+				if mimeType := strings.TrimSpace(string(b)); mimeType != "" {
+					return mimeType
+				}
+			}
+		}
+		for _, z := range zipMimeTypes {
+			if strings.HasPrefix(f.Name, z.prefix) {
+				return z.mimeType
+			}
+		}
+	}
+	return "application/zip"
+}
+
+// isSVG reports whether an XML or text document has an svg root element
+func isSVG(head []byte) bool {
+	s := strings.TrimSpace(strings.TrimPrefix(string(head), "\xef\xbb\xbf"))
+	for strings.HasPrefix(s, "<?") || strings.HasPrefix(s, "<!") {
+		// skip the XML declaration, comments and the doctype
+		end := strings.Index(s, ">")
+		if end < 0 {
+			return false
+		}
+		s = strings.TrimSpace(s[end+1:])
+	}
+	return strings.HasPrefix(s, "<svg") && len(s) > 4 && strings.ContainsRune(" \t\r\n>", rune(s[4]))
+}
+
+// matchesExtension cross-checks a detected type against the extension of
+// path, extensions without a known type match anything.
+func matchesExtension(path, mimeType string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	expected := slices.Clone(extensionMimeTypes[ext])
+	for typ, exts := range AllowedMimeTypes {
+		for _, e := range exts {
+			if strings.EqualFold(e, ext) {
+				expected = append(expected, typ)
+			}
+		}
+	}
+	if len(expected) == 0 {
+		return true
+	}
+
+	for _, pattern := range expected {
+		if matchMimeType(pattern, baseMimeType(mimeType)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package encode
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMimePolicyAllowed(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   MimePolicy
+		mimeType string
+		want     bool
+	}{
+		{name: "exact", policy: MimePolicy{Allow: []string{"image/png"}}, mimeType: "image/png", want: true},
+		{name: "not listed", policy: MimePolicy{Allow: []string{"image/png"}}, mimeType: "image/gif", want: false},
+		{name: "wildcard", policy: MimePolicy{Allow: []string{"image/*"}}, mimeType: "image/webp", want: true},
+		{name: "wildcard other type", policy: MimePolicy{Allow: []string{"image/*"}}, mimeType: "application/pdf", want: false},
+		{name: "parameters ignored", policy: MimePolicy{Allow: []string{"text/plain"}}, mimeType: "text/plain; charset=utf-8", want: true},
+		{name: "deny wins", policy: MimePolicy{Allow: []string{"image/*"}, Deny: []string{"image/svg+xml"}}, mimeType: "image/svg+xml", want: false},
+		{name: "deny only", policy: MimePolicy{Deny: []string{"application/*"}}, mimeType: "text/html", want: true},
+		{name: "allow all", policy: MimePolicy{Allow: []string{"*/*"}}, mimeType: "video/mp4", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Allowed(tt.mimeType); got != tt.want {
+				t.Errorf("Allowed(%q) = %v, want %v", tt.mimeType, got, tt.want)
+			}
+		})
+	}
+}
+
+func zipArchive(t *testing.T, names ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range names {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte("<xml/>"))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDetectMimeType(t *testing.T) {
+	gif, err := os.ReadFile("../../test_data/portrait.gif")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		content []byte
+		want    string
+	}{
+		{name: "gif", content: gif, want: "image/gif"},
+		{name: "webp", content: []byte("RIFF\x24\x00\x00\x00WEBPVP8L\x00\x00"), want: "image/webp"},
+		{name: "avif", content: []byte("\x00\x00\x00\x1cftypavif\x00\x00\x00\x00"), want: "image/avif"},
+		{name: "tiff", content: []byte("II*\x00\x08\x00\x00\x00"), want: "image/tiff"},
+		{name: "svg", content: []byte(`<svg xmlns="http://www.w3.org/2000/svg" width="1" height="1"/>`), want: "image/svg+xml"},
+		{name: "svg with prolog", content: []byte("<?xml version=\"1.0\"?>\n<!-- logo -->\n<svg>\n</svg>"), want: "image/svg+xml"},
+		{name: "xml", content: []byte(`<?xml version="1.0"?><svgish/>`), want: "text/xml; charset=utf-8"},
+		{name: "zip", content: zipArchive(t, "a.txt"), want: "application/zip"},
+		{name: "docx", content: zipArchive(t, "[Content_Types].xml", "_rels/.rels", "word/document.xml"), want: "application/vnd.openxmlformats-officedocument.wordprocessingml.document"},
+		{name: "xlsx", content: zipArchive(t, "[Content_Types].xml", "xl/workbook.xml"), want: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"},
+		{name: "text", content: []byte("package main\n"), want: "text/plain; charset=utf-8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DetectMimeType(bytes.NewReader(tt.content), int64(len(tt.content)))
+			if err != nil {
+				t.Fatalf("DetectMimeType() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("DetectMimeType() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileEncoderMimePolicy(t *testing.T) {
+	dir := t.TempDir()
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	write := func(name string, content []byte) string {
+		fpath := filepath.Join(dir, name)
+		if err := os.WriteFile(fpath, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return fpath
+	}
+	allowAll := []EncoderOpt{WithMimePolicy(MimePolicy{Allow: []string{"*/*"}})}
+
+	tests := []struct {
+		name    string
+		fpath   string
+		opts    []EncoderOpt
+		wantErr error
+	}{
+		{name: "gif allowed by default", fpath: "../../test_data/portrait.gif"},
+		{name: "svg denied by default", fpath: write("logo.svg", []byte("<svg></svg>")), wantErr: ErrMimeTypeNotAllowed},
+		{name: "svg allowed by wildcard", fpath: write("icon.svg", []byte("<svg></svg>")), opts: []EncoderOpt{WithMimePolicy(MimePolicy{Allow: []string{"image/*"}})}},
+		{name: "png denied", fpath: write("image.png", png), opts: []EncoderOpt{WithMimePolicy(MimePolicy{Deny: []string{"image/png"}})}, wantErr: ErrMimeTypeNotAllowed},
+		{name: "extension mismatch", fpath: write("report.pdf", png), wantErr: ErrMimeTypeMismatch},
+		{name: "unknown extension", fpath: write("image.bin", png)},
+		{name: "zip", fpath: write("archive.zip", zipArchive(t, "a.txt")), opts: allowAll},
+		{name: "docx named zip", fpath: write("report.zip", zipArchive(t, "[Content_Types].xml", "word/document.xml")), opts: allowAll},
+		{name: "pdf named zip", fpath: write("report-pdf.zip", []byte("%PDF-1.7\n")), opts: allowAll, wantErr: ErrMimeTypeMismatch},
+		{name: "go source", fpath: write("main.go", []byte("package main\n"))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]EncoderOpt{WithFormatEncoder(NewBase64Encoder(""))}, tt.opts...)
+			_, err := NewFileEncoder(tt.fpath, opts...).Encode()
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Encode() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package encode
+
+import (
+	"io"
+	"net/url"
+)
+
+const upperHex = "0123456789ABCDEF"
+
+// PercentEncoder escapes every byte outside the RFC 3986 unreserved set as %XX,
+// it is the encoding of non-base64 data URIs.
+type PercentEncoder struct{}
+
+func NewPercentEncoder() FormatCodec {
+	return &PercentEncoder{}
+}
+
+func (p *PercentEncoder) Encode(dst []byte, src []byte) {
+	copy(dst, percentEncode(nil, src))
+}
+
+func (p *PercentEncoder) EncodeToString(src []byte) string {
+	return string(percentEncode(make([]byte, 0, len(src)), src))
+}
+
+func (p *PercentEncoder) NewEncoder(w io.Writer) io.WriteCloser {
+	return nopCloser{percentWriter{w: w}}
+}
+
+func (p *PercentEncoder) Decode(dst []byte, src []byte) (int, error) {
+	decoded, err := p.DecodeString(string(src))
+	return decodeInto(dst, decoded, err)
+}
+
+func (p *PercentEncoder) DecodeString(src string) ([]byte, error) {
+	s, err := url.PathUnescape(src)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(s), nil
+}
+
+// DataURIEncoding marks percent encoding, which has no marker in a data URI
+func (p *PercentEncoder) DataURIEncoding() (string, bool) {
+	return "", true
+}
+
+func percentEncode(dst []byte, src []byte) []byte {
+	for _, c := range src {
+		if isUnreserved(c) {
+			dst = append(dst, c)
+			continue
+		}
+		dst = append(dst, '%', upperHex[c>>4], upperHex[c&0x0f])
+	}
+	return dst
+}
+
+func isUnreserved(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	}
+	return c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+// percentWriter escapes every write, each byte is encoded on its own so no state is kept
+type percentWriter struct {
+	w io.Writer
+}
+
+func (p percentWriter) Write(b []byte) (int, error) {
+	if _, err := p.w.Write(percentEncode(make([]byte, 0, len(b)), b)); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
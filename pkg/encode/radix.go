@@ -0,0 +1,104 @@
+package encode
+
+import "fmt"
+
+// radixCodec encodes bytes as one big-endian number written in the digits of
+// alphabet, the way base58 does. Leading zero bytes are kept as leading zero
+// digits so the encoding is reversible.
+type radixCodec struct {
+	name      string
+	alphabet  string
+	decodeMap [256]int
+}
+
+func newRadixCodec(name string, alphabet string, caseInsensitive bool) *radixCodec {
+	r := &radixCodec{name: name, alphabet: alphabet}
+	for i := range r.decodeMap {
+		r.decodeMap[i] = -1
+	}
+	for i := 0; i < len(alphabet); i++ {
+		c := alphabet[i]
+		r.decodeMap[c] = i
+		if caseInsensitive && c >= 'a' && c <= 'z' {
+			r.decodeMap[c-'a'+'A'] = i
+		}
+	}
+	return r
+}
+
+func (r *radixCodec) base() int {
+	return len(r.alphabet)
+}
+
+func (r *radixCodec) encode(src []byte) []byte {
+	zeros := 0
+	for zeros < len(src) && src[zeros] == 0 {
+		zeros++
+	}
+
+	// little-endian digits of the number
+	digits := make([]byte, 0, len(src)*2)
+	for _, b := range src[zeros:] {
+		carry := int(b)
+		for j := 0; j < len(digits); j++ {
+			carry += int(digits[j]) << 8
+			digits[j] = byte(carry % r.base())
+			carry /= r.base()
+		}
+		for carry > 0 {
+			digits = append(digits, byte(carry%r.base()))
+			carry /= r.base()
+		}
+	}
+
+	dst := make([]byte, zeros+len(digits))
+	for i := 0; i < zeros; i++ {
+		dst[i] = r.alphabet[0]
+	}
+	for i := range digits {
+		dst[zeros+i] = r.alphabet[digits[len(digits)-1-i]]
+	}
+	return dst
+}
+
+func (r *radixCodec) decode(src []byte) ([]byte, error) {
+	zeros := 0
+	for zeros < len(src) && src[zeros] == r.alphabet[0] {
+		zeros++
+	}
+
+	// little-endian bytes of the number
+	out := make([]byte, 0, len(src))
+	for pos, c := range src[zeros:] {
+		carry := r.decodeMap[c]
+		if carry < 0 {
+			return nil, fmt.Errorf("illegal %s data at input byte %d", r.name, zeros+pos)
+		}
+		for j := 0; j < len(out); j++ {
+			carry += int(out[j]) * r.base()
+			out[j] = byte(carry & 0xff)
+			carry >>= 8
+		}
+		for carry > 0 {
+			out = append(out, byte(carry&0xff))
+			carry >>= 8
+		}
+	}
+
+	dst := make([]byte, zeros+len(out))
+	for i := range out {
+		dst[zeros+i] = out[len(out)-1-i]
+	}
+	return dst, nil
+}
+
+// decodeInto decodes src into dst like the stdlib Decode methods
+func decodeInto(dst []byte, decoded []byte, err error) (int, error) {
+	if err != nil {
+		return 0, err
+	}
+	if len(dst) < len(decoded) {
+		return 0, fmt.Errorf("destination too short, need %d bytes", len(decoded))
+	}
+	return copy(dst, decoded), nil
+}
@@ -0,0 +1,229 @@
+package encode
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Padding values for WithPadding
+const (
+	StdPadding rune = '='
+	NoPadding  rune = -1
+)
+
+// FormatOptions holds the options a FormatFactory is created with.
+// Zero values keep the encoder defaults.
+type FormatOptions struct {
+	// Alphabet replaces the encoder alphabet, it must have the encoder base as length
+	Alphabet string
+	// Padding is the padding character, NoPadding disables padding
+	Padding rune
+	// LineWrap breaks the output into lines of this many characters, applied by Lookup
+	LineWrap int
+}
+
+type FormatOpt func(*FormatOptions)
+
+func WithAlphabet(alphabet string) FormatOpt {
+	return func(o *FormatOptions) {
+		o.Alphabet = alphabet
+	}
+}
+
+func WithPadding(padding rune) FormatOpt {
+	return func(o *FormatOptions) {
+		o.Padding = padding
+	}
+}
+
+func WithLineWrap(width int) FormatOpt {
+	return func(o *FormatOptions) {
+		o.LineWrap = width
+	}
+}
+
+// FormatFactory creates a format encoder, it returns an error for options it does not support
+type FormatFactory func(opts FormatOptions) (FormatEncoder, error)
+
+var (
+	registryMu     sync.RWMutex
+	formatRegistry = map[string]FormatFactory{}
+)
+
+func init() {
+	Register("base64", base64Factory(base64.StdEncoding, stdBase64Alphabet))
+	Register("base64url", base64Factory(base64.URLEncoding, urlBase64Alphabet))
+	Register("base64raw", base64Factory(base64.RawStdEncoding, stdBase64Alphabet))
+	Register("base64rawurl", base64Factory(base64.RawURLEncoding, urlBase64Alphabet))
+	Register("base32", base32Factory(base32.StdEncoding, stdBase32Alphabet))
+	Register("base32hex", base32Factory(base32.HexEncoding, hexBase32Alphabet))
+	Register("base32crockford", plainFactory("base32crockford", NewCrockfordBase32Encoder))
+	Register("zbase32", plainFactory("zbase32", NewZBase32Encoder))
+	Register("base36", radixFactory("base36", Base36Alphabet, true, func(c *radixCodec) FormatEncoder { return &Base36Encoder{codec: c} }))
+	Register("base58", radixFactory("base58", Base58Alphabet, false, func(c *radixCodec) FormatEncoder { return &Base58Encoder{codec: c} }))
+	Register("ascii85", plainFactory("ascii85", NewAscii85Encoder))
+	Register("z85", plainFactory("z85", NewZ85Encoder))
+	Register("hex", plainFactory("hex", NewHexEncoder))
+	Register("percent", plainFactory("percent", NewPercentEncoder))
+	Register("gob", plainFactory("gob", NewGobEncoder))
+
+	// a snapshot of the built-in encoders, Register never touches it again
+	AvailableFormatEncoder = names()
+}
+
+// Register makes a format encoder available to Lookup under name.
+// Registering an existing name replaces its factory.
+func Register(name string, factory FormatFactory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	formatRegistry[strings.ToLower(name)] = factory
+}
+
+// Lookup returns a new format encoder by name, e.g. "base64url" or "base58"
+func Lookup(name string, opts ...FormatOpt) (FormatEncoder, error) {
+	registryMu.RLock()
+	factory, ok := formatRegistry[strings.ToLower(name)]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormatEncoder, name)
+	}
+
+	var o FormatOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	enc, err := factory(o)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.LineWrap > 0 {
+		enc = &LineWrapEncoder{enc: enc, width: o.LineWrap}
+	}
+	return enc, nil
+}
+
+// Names returns the sorted names of all format encoders Lookup knows
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	return names()
+}
+
+func names() []string {
+	names := make([]string, 0, len(formatRegistry))
+	for name := range formatRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func base64Factory(def *base64.Encoding, alphabet string) FormatFactory {
+	return func(o FormatOptions) (FormatEncoder, error) {
+		enc, symbols := def, alphabet
+		std := def == base64.StdEncoding
+		if o.Alphabet != "" {
+			if err := checkAlphabet("base64", o.Alphabet, 64); err != nil {
+				return nil, err
+			}
+			enc = base64.NewEncoding(o.Alphabet)
+			symbols = o.Alphabet
+			std = std && o.Alphabet == stdBase64Alphabet
+		}
+		if o.Padding != 0 {
+			if err := checkPadding("base64", symbols, o.Padding); err != nil {
+				return nil, err
+			}
+			enc = enc.WithPadding(o.Padding)
+			std = std && o.Padding == StdPadding
+		}
+		return &Base64Encoder{enc: enc, std: std}, nil
+	}
+}
+
+func base32Factory(def *base32.Encoding, alphabet string) FormatFactory {
+	return func(o FormatOptions) (FormatEncoder, error) {
+		enc, symbols := def, alphabet
+		if o.Alphabet != "" {
+			if err := checkAlphabet("base32", o.Alphabet, 32); err != nil {
+				return nil, err
+			}
+			enc = base32.NewEncoding(o.Alphabet)
+			symbols = o.Alphabet
+		}
+		if o.Padding != 0 {
+			if err := checkPadding("base32", symbols, o.Padding); err != nil {
+				return nil, err
+			}
+			enc = enc.WithPadding(o.Padding)
+		}
+		return &Base32Encoder{enc: enc}, nil
+	}
+}
+
+func radixFactory(name string, alphabet string, caseInsensitive bool, newEncoder func(*radixCodec) FormatEncoder) FormatFactory {
+	return func(o FormatOptions) (FormatEncoder, error) {
+		if o.Padding != 0 {
+			return nil, fmt.Errorf("%w: %s has no padding", ErrInvalidFormatOption, name)
+		}
+		digits := alphabet
+		if o.Alphabet != "" {
+			if err := checkAlphabet(name, o.Alphabet, len(alphabet)); err != nil {
+				return nil, err
+			}
+			digits = o.Alphabet
+		}
+
+		return newEncoder(newRadixCodec(name, digits, caseInsensitive)), nil
+	}
+}
+
+// checkAlphabet rejects what the encodings would panic on, or decode
+// ambiguously: a wrong length, repeated symbols and line breaks.
+func checkAlphabet(name, alphabet string, size int) error {
+	if len(alphabet) != size {
+		return fmt.Errorf("%w: %s alphabet must have %d characters", ErrInvalidFormatOption, name, size)
+	}
+	for i := 0; i < len(alphabet); i++ {
+		c := alphabet[i]
+		if c == '\r' || c == '\n' {
+			return fmt.Errorf("%w: %s alphabet must not contain line breaks", ErrInvalidFormatOption, name)
+		}
+		if strings.IndexByte(alphabet[i+1:], c) >= 0 {
+			return fmt.Errorf("%w: %s alphabet repeats %q", ErrInvalidFormatOption, name, c)
+		}
+	}
+	return nil
+}
+
+// checkPadding rejects a padding character the encoding would panic on
+func checkPadding(name, alphabet string, padding rune) error {
+	if padding == NoPadding {
+		return nil
+	}
+	if padding < 0 || padding > 0xff || padding == '\r' || padding == '\n' {
+		return fmt.Errorf("%w: %s padding %q cannot be used as padding", ErrInvalidFormatOption, name, padding)
+	}
+	if strings.IndexByte(alphabet, byte(padding)) >= 0 {
+		return fmt.Errorf("%w: %s padding %q is part of the alphabet", ErrInvalidFormatOption, name, padding)
+	}
+	return nil
+}
+
+// plainFactory registers an encoder that supports no options besides LineWrap
+func plainFactory(name string, newEncoder func() FormatCodec) FormatFactory {
+	return func(o FormatOptions) (FormatEncoder, error) {
+		if o.Alphabet != "" || o.Padding != 0 {
+			return nil, fmt.Errorf("%w: %s does not support a custom alphabet or padding", ErrInvalidFormatOption, name)
+		}
+		return newEncoder(), nil
+	}
+}
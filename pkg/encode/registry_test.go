@@ -0,0 +1,292 @@
+package encode
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestLookupKnownVectors(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     []byte
+		encoded string
+	}{
+		{name: "base58", src: []byte("Hello World!"), encoded: "2NEpo7TZRRrLZSi2U"},
+		{name: "base58", src: []byte{0x00, 0x00, 0x28, 0x7f, 0xb4, 0xcd}, encoded: "11233QC4"},
+		{name: "base36", src: []byte("Hello World"), encoded: "azw5bz2xp56m4qyck"},
+		{name: "z85", src: []byte{0x86, 0x4f, 0xd2, 0x6f, 0xb5, 0x59, 0xf7, 0x5b}, encoded: "HelloWorld"},
+		{name: "ascii85", src: []byte("hello"), encoded: "BOu!rDZ"},
+		{name: "base32crockford", src: []byte("hello world"), encoded: "D1JPRV3F41VPYWKCCG"},
+		{name: "base64url", src: []byte{0xfb, 0xff}, encoded: "-_8="},
+		{name: "base64raw", src: []byte{0xfb, 0xff}, encoded: "+/8"},
+		{name: "base64rawurl", src: []byte{0xfb, 0xff}, encoded: "-_8"},
+		{name: "base32hex", src: []byte("f"), encoded: "CO======"},
+		{name: "zbase32", src: []byte{0xf0, 0xbf, 0xc7}, encoded: "6n9hq"},
+		{name: "zbase32", src: []byte("hello"), encoded: "pb1sa5dx"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enc, err := Lookup(tt.name)
+			if err != nil {
+				t.Fatalf("Lookup(%q) error = %v", tt.name, err)
+			}
+			if got := enc.EncodeToString(tt.src); got != tt.encoded {
+				t.Errorf("EncodeToString(%x) = %q, want %q", tt.src, got, tt.encoded)
+			}
+
+			got, err := enc.(FormatDecoder).DecodeString(tt.encoded)
+			if err != nil {
+				t.Fatalf("DecodeString(%q) error = %v", tt.encoded, err)
+			}
+			if !bytes.Equal(got, tt.src) {
+				t.Errorf("DecodeString(%q) = %x, want %x", tt.encoded, got, tt.src)
+			}
+		})
+	}
+}
+
+func TestLookupRoundTrip(t *testing.T) {
+	inputs := [][]byte{
+		{},
+		{0x00},
+		{0x00, 0x00, 0x01},
+		[]byte("a"),
+		[]byte("ab"),
+		[]byte("abc"),
+		[]byte("abcd"),
+		[]byte("The quick brown fox jumps over the lazy dog"),
+		bytes.Repeat([]byte{0xff, 0x00, 0x80}, 50),
+	}
+	for _, name := range Names() {
+		t.Run(name, func(t *testing.T) {
+			enc, err := Lookup(name)
+			if err != nil {
+				t.Fatalf("Lookup(%q) error = %v", name, err)
+			}
+			dec, ok := enc.(FormatDecoder)
+			if !ok {
+				t.Fatalf("Lookup(%q) does not implement FormatDecoder", name)
+			}
+			for _, src := range inputs {
+				encoded := enc.EncodeToString(src)
+				got, err := dec.DecodeString(encoded)
+				if err != nil {
+					t.Fatalf("DecodeString(%q) error = %v", encoded, err)
+				}
+				if !bytes.Equal(got, src) {
+					t.Errorf("DecodeString(EncodeToString(%x)) = %x", src, got)
+				}
+			}
+		})
+	}
+}
+
+func TestCrockfordDecodeAliases(t *testing.T) {
+	got, err := NewCrockfordBase32Encoder().DecodeString("d1jprv3f-41vpywkccg")
+	if err != nil || string(got) != "hello world" {
+		t.Errorf("DecodeString() = %q, %v, want %q", got, err, "hello world")
+	}
+
+	got, err = NewCrockfordBase32Encoder().DecodeString("OO")
+	if err != nil || !bytes.Equal(got, []byte{0x00}) {
+		t.Errorf("DecodeString(OO) = %x, %v, want 00", got, err)
+	}
+}
+
+func TestZBase32DecodeIgnoresCase(t *testing.T) {
+	got, err := NewZBase32Encoder().DecodeString("PB1SA5DX")
+	if err != nil || string(got) != "hello" {
+		t.Errorf("DecodeString() = %q, %v, want %q", got, err, "hello")
+	}
+}
+
+func TestLookupUnknown(t *testing.T) {
+	if _, err := Lookup("base1000"); !errors.Is(err, ErrUnknownFormatEncoder) {
+		t.Errorf("Lookup() error = %v, want %v", err, ErrUnknownFormatEncoder)
+	}
+}
+
+func TestLookupOptions(t *testing.T) {
+	src := []byte("The quick brown fox jumps over the lazy dog")
+	tests := []struct {
+		name    string
+		format  string
+		opts    []FormatOpt
+		want    string
+		wantErr error
+	}{
+		{
+			name:   "base64 without padding",
+			format: "base64",
+			opts:   []FormatOpt{WithPadding(NoPadding)},
+			want:   "VGhlIHF1aWNrIGJyb3duIGZveCBqdW1wcyBvdmVyIHRoZSBsYXp5IGRvZw",
+		},
+		{
+			name:   "base32 with custom padding",
+			format: "base32",
+			opts:   []FormatOpt{WithPadding('*')},
+			want:   "KRUGKIDROVUWG2ZAMJZG653OEBTG66BANJ2W24DTEBXXMZLSEB2GQZJANRQXU6JAMRXWO***",
+		},
+		{
+			name:   "base64 wrapped at 20",
+			format: "base64",
+			opts:   []FormatOpt{WithLineWrap(20)},
+			want:   "VGhlIHF1aWNrIGJyb3du\nIGZveCBqdW1wcyBvdmVy\nIHRoZSBsYXp5IGRvZw==",
+		},
+		{
+			name:    "base64 alphabet too short",
+			format:  "base64",
+			opts:    []FormatOpt{WithAlphabet("abc")},
+			wantErr: ErrInvalidFormatOption,
+		},
+		{
+			name:    "hex has no alphabet",
+			format:  "hex",
+			opts:    []FormatOpt{WithAlphabet("0123456789ABCDEF")},
+			wantErr: ErrInvalidFormatOption,
+		},
+		{
+			name:    "base64 padding in alphabet",
+			format:  "base64",
+			opts:    []FormatOpt{WithPadding('A')},
+			wantErr: ErrInvalidFormatOption,
+		},
+		{
+			name:    "base64url padding in alphabet",
+			format:  "base64url",
+			opts:    []FormatOpt{WithPadding('-')},
+			wantErr: ErrInvalidFormatOption,
+		},
+		{
+			name:    "base64 padding in custom alphabet",
+			format:  "base64",
+			opts:    []FormatOpt{WithAlphabet("ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+*"), WithPadding('*')},
+			wantErr: ErrInvalidFormatOption,
+		},
+		{
+			name:    "base64 newline padding",
+			format:  "base64",
+			opts:    []FormatOpt{WithPadding('\n')},
+			wantErr: ErrInvalidFormatOption,
+		},
+		{
+			name:    "base64 multibyte padding",
+			format:  "base64",
+			opts:    []FormatOpt{WithPadding('€')},
+			wantErr: ErrInvalidFormatOption,
+		},
+		{
+			name:    "base64 repeated symbol",
+			format:  "base64",
+			opts:    []FormatOpt{WithAlphabet("AACDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/")},
+			wantErr: ErrInvalidFormatOption,
+		},
+		{
+			name:    "base64 newline in alphabet",
+			format:  "base64",
+			opts:    []FormatOpt{WithAlphabet("\nBCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/")},
+			wantErr: ErrInvalidFormatOption,
+		},
+		{
+			name:    "base32 padding in alphabet",
+			format:  "base32",
+			opts:    []FormatOpt{WithPadding('A')},
+			wantErr: ErrInvalidFormatOption,
+		},
+		{
+			name:    "base32hex padding in alphabet",
+			format:  "base32hex",
+			opts:    []FormatOpt{WithPadding('0')},
+			wantErr: ErrInvalidFormatOption,
+		},
+		{
+			name:    "base32 repeated symbol",
+			format:  "base32",
+			opts:    []FormatOpt{WithAlphabet("ABCDEFGHIJKLMNOPQRSTUVWXYZ23456A")},
+			wantErr: ErrInvalidFormatOption,
+		},
+		{
+			name:    "base58 repeated symbol",
+			format:  "base58",
+			opts:    []FormatOpt{WithAlphabet("113456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz")},
+			wantErr: ErrInvalidFormatOption,
+		},
+		{
+			name:   "base58 flickr alphabet",
+			format: "base58",
+			opts:   []FormatOpt{WithAlphabet("123456789abcdefghijkmnopqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ")},
+			want:   "7dCHooxTXkJcd3Wa1PN2RVyhsdxJKyxHeTzZREHWiBWMjcHZGgfGbghDqCX",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enc, err := Lookup(tt.format, tt.opts...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Lookup() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if got := enc.EncodeToString(src); got != tt.want {
+				t.Errorf("EncodeToString() = %q, want %q", got, tt.want)
+			}
+
+			got, err := enc.(FormatDecoder).DecodeString(tt.want)
+			if err != nil || !bytes.Equal(got, src) {
+				t.Errorf("DecodeString() = %q, %v", got, err)
+			}
+		})
+	}
+}
+
+func TestLineWrapStream(t *testing.T) {
+	for _, format := range []string{"base64", "gob"} {
+		enc, err := Lookup(format, WithLineWrap(8))
+		if err != nil {
+			t.Fatalf("Lookup(%q) error = %v", format, err)
+		}
+
+		src := bytes.Repeat([]byte("stream"), 20)
+		var got bytes.Buffer
+		w := enc.(StreamEncoder).NewEncoder(&got)
+		for _, chunk := range bytes.SplitAfter(src, []byte("m")) {
+			w.Write(chunk)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+
+		if want := enc.EncodeToString(src); got.String() != want {
+			t.Errorf("%s: streamed = %q, want %q", format, got.String(), want)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	t.Cleanup(func() {
+		registryMu.Lock()
+		defer registryMu.Unlock()
+		delete(formatRegistry, "upper-hex")
+	})
+
+	Register("upper-hex", func(o FormatOptions) (FormatEncoder, error) {
+		return NewBase32Encoder("0123456789ABCDEFGHIJKLMNOPQRSTUV"), nil
+	})
+
+	enc, err := Lookup("UPPER-HEX")
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	if got := enc.EncodeToString([]byte("f")); got != "CO======" {
+		t.Errorf("EncodeToString() = %q", got)
+	}
+	if !slices.Contains(Names(), "upper-hex") {
+		t.Errorf("Names() = %v, want it to contain upper-hex", Names())
+	}
+	if slices.Contains(AvailableFormatEncoder, "upper-hex") {
+		t.Errorf("AvailableFormatEncoder = %v, want only the built-in encoders", AvailableFormatEncoder)
+	}
+}
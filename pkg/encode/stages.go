@@ -0,0 +1,43 @@
+package encode
+
+// Data passes through optional stages between the source and the format
+// encoder: compression first, as ciphertext does not compress, then encryption.
+
+// sealStages applies the configured stages to src before format encoding
+func sealStages(src []byte, compression string, enc encryption) ([]byte, error) {
+	if compression != "" {
+		c, err := lookupCompression(compression)
+		if err != nil {
+			return nil, err
+		}
+		if src, err = c.compress(src); err != nil {
+			return nil, err
+		}
+	}
+
+	if enc.enabled() {
+		return enc.encrypt(src)
+	}
+	return src, nil
+}
+
+// openStages reverses sealStages on format decoded data
+func openStages(src []byte, compression string, enc encryption) ([]byte, error) {
+	if enc.enabled() {
+		var err error
+		if src, err = enc.decrypt(src); err != nil {
+			return nil, err
+		}
+	}
+
+	if compression != "" {
+		return decompress(src, true)
+	}
+
+	// compressed payloads carry a header and a checksum, they never come back
+	// compressed because WithCompression was left out
+	if isCompressed(src) {
+		return decompress(src, false)
+	}
+	return src, nil
+}
@@ -1,15 +1,20 @@
 package encode
 
 import (
-	"fmt"
+	"io"
 
-	"golang.design/x/clipboard"
+	"github.com/vldcreation/helpme-package/pkg/clipboard"
 )
 
 type TextEncoder struct {
 	src             []byte
 	copyToClipboard bool
+	clipboard       clipboard.Clipboard
 	formatEncoder   FormatEncoder
+	compression     string
+	encryption      encryption
+	digest          string
+	hmacKey         []byte
 }
 
 func NewTextEncoder(text string, opts ...EncoderOpt) Encoder {
@@ -35,32 +40,76 @@ func (t *TextEncoder) Encode() (string, error) {
 		return "", ErrSourceTextNotSet
 	}
 
-	if t.formatEncoder == nil {
+	// a digest is hex formatted without a format encoder
+	if t.formatEncoder == nil && t.digest == "" {
 		return "", ErrEncoderNotSet
 	}
 
-	return t.encode(), nil
+	encoded, err := t.encode()
+	if err != nil {
+		return "", wrapOpError(err, "encode", "", t.formatEncoder)
+	}
+	return encoded, nil
 }
 
-func (t *TextEncoder) encode() string {
-	encoded := t.formatEncoder.EncodeToString(t.src)
+// EncodeTo writes the encoded text to w
+func (t *TextEncoder) EncodeTo(w io.Writer) error {
+	encoded, err := t.Encode()
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, encoded)
+	return err
+}
+
+func (t *TextEncoder) encode() (string, error) {
+	var encoded string
+	if t.digest != "" {
+		hasher := &Hasher{digest: t.digest, hmacKey: t.hmacKey, formatEncoder: t.formatEncoder}
+		sum, err := hasher.SumString(string(t.src))
+		if err != nil {
+			return "", err
+		}
+		encoded = sum
+	} else {
+		src, err := sealStages(t.src, t.compression, t.encryption)
+		if err != nil {
+			return "", err
+		}
+		encoded = t.formatEncoder.EncodeToString(src)
+	}
+
 	if t.copyToClipboard {
-		copyTextToClipboard(encoded)
+		if err := writeClipboard(t.clipboard, clipboard.FormatText, []byte(encoded)); err != nil {
+			return "", err
+		}
 	}
-	return encoded
+	return encoded, nil
 }
 
-func copyTextToClipboard(text string) error {
-	err := clipboard.Init()
-	if err != nil {
-		return err
+func (t *TextEncoder) Decode() ([]byte, error) {
+	if t.src == nil {
+		return nil, ErrSourceTextNotSet
+	}
+
+	if t.formatEncoder == nil {
+		return nil, ErrEncoderNotSet
 	}
 
-	changed := clipboard.Write(clipboard.FmtText, []byte(text))
-	select {
-	case <-changed:
-		fmt.Println(`"text data" is no longer available from clipboard.`)
+	decoder, ok := t.formatEncoder.(FormatDecoder)
+	if !ok {
+		return nil, ErrDecoderNotSupported
 	}
 
-	return nil
+	decoded, err := decoder.DecodeString(string(t.src))
+	if err != nil {
+		return nil, wrapOpError(err, "decode", "", t.formatEncoder)
+	}
+
+	opened, err := openStages(decoded, t.compression, t.encryption)
+	if err != nil {
+		return nil, wrapOpError(err, "decode", "", t.formatEncoder)
+	}
+	return opened, nil
 }
@@ -0,0 +1,139 @@
+package encode
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// ValueCodec serializes Go values to bytes and back
+type ValueCodec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// valueCodecFunc adapts a marshal and unmarshal function pair to ValueCodec
+type valueCodecFunc struct {
+	marshal   func(v any) ([]byte, error)
+	unmarshal func(data []byte, v any) error
+}
+
+func (f valueCodecFunc) Marshal(v any) ([]byte, error) {
+	return f.marshal(v)
+}
+
+func (f valueCodecFunc) Unmarshal(data []byte, v any) error {
+	return f.unmarshal(data, v)
+}
+
+var valueCodecs = map[string]ValueCodec{
+	"gob":     valueCodecFunc{marshal: gobMarshal, unmarshal: gobUnmarshal},
+	"json":    valueCodecFunc{marshal: json.Marshal, unmarshal: json.Unmarshal},
+	"cbor":    valueCodecFunc{marshal: cbor.Marshal, unmarshal: cbor.Unmarshal},
+	"msgpack": valueCodecFunc{marshal: msgpack.Marshal, unmarshal: msgpack.Unmarshal},
+}
+
+// ValueCodecs returns the names WithValueCodec accepts
+func ValueCodecs() []string {
+	names := make([]string, 0, len(valueCodecs))
+	for name := range valueCodecs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// gobMarshal encodes v into a fresh gob stream, so the type information is
+// repeated in every result and each can be decoded on its own.
+func gobMarshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func gobUnmarshal(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// ValueEncoder serializes Go values with a ValueCodec and format encodes the
+// result, e.g. a struct to msgpack to base64 and back.
+type ValueEncoder struct {
+	codec         string
+	formatEncoder FormatEncoder
+	compression   string
+	encryption    encryption
+}
+
+// NewValueEncoder returns a gob and base64 value encoder unless opts say otherwise
+func NewValueEncoder(opts ...EncoderOpt) *ValueEncoder {
+	v := &ValueEncoder{codec: "gob"}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+func (v *ValueEncoder) ApplyOpt(opts ...EncoderOpt) {
+	for _, opt := range opts {
+		opt(v)
+	}
+}
+
+// EncodeValue serializes value and returns it format encoded
+func (v *ValueEncoder) EncodeValue(value any) (string, error) {
+	codec, err := lookupValueCodec(v.codec)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := codec.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", v.codec, err)
+	}
+
+	if data, err = sealStages(data, v.compression, v.encryption); err != nil {
+		return "", err
+	}
+	return v.format().EncodeToString(data), nil
+}
+
+// DecodeValue reverses EncodeValue into value, which must be a pointer
+func (v *ValueEncoder) DecodeValue(encoded string, value any) error {
+	codec, err := lookupValueCodec(v.codec)
+	if err != nil {
+		return err
+	}
+
+	data, err := decodePayload(encoded, v.format(), v.compression, v.encryption)
+	if err != nil {
+		return err
+	}
+
+	if err := codec.Unmarshal(data, value); err != nil {
+		return fmt.Errorf("%s: %w", v.codec, err)
+	}
+	return nil
+}
+
+func (v *ValueEncoder) format() FormatEncoder {
+	if v.formatEncoder == nil {
+		return NewBase64Encoder("")
+	}
+	return v.formatEncoder
+}
+
+func lookupValueCodec(name string) (ValueCodec, error) {
+	codec, ok := valueCodecs[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownValueCodec, name)
+	}
+	return codec, nil
+}
@@ -0,0 +1,76 @@
+package encode
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type valueFixture struct {
+	Name    string            `json:"name" cbor:"name" msgpack:"name"`
+	Count   int               `json:"count" cbor:"count" msgpack:"count"`
+	Tags    []string          `json:"tags" cbor:"tags" msgpack:"tags"`
+	Labels  map[string]string `json:"labels" cbor:"labels" msgpack:"labels"`
+	Created time.Time         `json:"created" cbor:"created" msgpack:"created"`
+}
+
+func TestValueEncoderRoundTrip(t *testing.T) {
+	want := valueFixture{
+		Name:    "helpme",
+		Count:   42,
+		Tags:    []string{"encode", "value"},
+		Labels:  map[string]string{"env": "ci"},
+		Created: time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	z85, _ := Lookup("z85")
+	for _, codec := range ValueCodecs() {
+		for name, opts := range map[string][]EncoderOpt{
+			"base64":     nil,
+			"z85":        {WithFormatEncoder(z85)},
+			"compressed": {WithFormatEncoder(NewHexEncoder()), WithCompression("zlib")},
+		} {
+			t.Run(codec+"/"+name, func(t *testing.T) {
+				enc := NewValueEncoder(append([]EncoderOpt{WithValueCodec(codec)}, opts...)...)
+
+				encoded, err := enc.EncodeValue(want)
+				if err != nil {
+					t.Fatalf("EncodeValue() error = %v", err)
+				}
+				// every call starts from a fresh buffer
+				if again, _ := enc.EncodeValue(want); again != encoded {
+					t.Errorf("EncodeValue() differs on the second call")
+				}
+
+				var got valueFixture
+				if err := enc.DecodeValue(encoded, &got); err != nil {
+					t.Fatalf("DecodeValue() error = %v", err)
+				}
+				// codecs differ in the location they decode times into
+				if !got.Created.Equal(want.Created) {
+					t.Errorf("DecodeValue() Created = %v, want %v", got.Created, want.Created)
+				}
+				got.Created = want.Created
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("DecodeValue() = %+v, want %+v", got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestValueEncoderErrors(t *testing.T) {
+	if _, err := NewValueEncoder(WithValueCodec("protobuf")).EncodeValue(1); !errors.Is(err, ErrUnknownValueCodec) {
+		t.Errorf("EncodeValue() error = %v, want %v", err, ErrUnknownValueCodec)
+	}
+
+	if _, err := NewValueEncoder(WithValueCodec("json")).EncodeValue(make(chan int)); err == nil {
+		t.Errorf("EncodeValue() of a channel expected error")
+	}
+
+	var n int
+	if err := NewValueEncoder().DecodeValue("bm90IGdvYg==", &n); err == nil {
+		t.Errorf("DecodeValue() of invalid gob expected error")
+	}
+}
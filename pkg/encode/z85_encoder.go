@@ -0,0 +1,92 @@
+package encode
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Z85Alphabet is the ZeroMQ Z85 alphabet
+const Z85Alphabet = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ.-:+=^!/*?&<>()[]{}@%$#"
+
+// Z85Encoder implements ZeroMQ Z85. Input whose length is not a multiple of
+// four is accepted like ascii85 does: the last partial group of n bytes is
+// written as n+1 characters.
+type Z85Encoder struct {
+	decodeMap [256]int
+}
+
+func NewZ85Encoder() FormatCodec {
+	z := &Z85Encoder{}
+	for i := range z.decodeMap {
+		z.decodeMap[i] = -1
+	}
+	for i := 0; i < len(Z85Alphabet); i++ {
+		z.decodeMap[Z85Alphabet[i]] = i
+	}
+	return z
+}
+
+// Encode copies the encoding of src into dst, which must be large enough
+func (z *Z85Encoder) Encode(dst []byte, src []byte) {
+	copy(dst, z.encode(src))
+}
+
+func (z *Z85Encoder) EncodeToString(src []byte) string {
+	return string(z.encode(src))
+}
+
+func (z *Z85Encoder) Decode(dst []byte, src []byte) (int, error) {
+	decoded, err := z.decode(src)
+	return decodeInto(dst, decoded, err)
+}
+
+func (z *Z85Encoder) DecodeString(src string) ([]byte, error) {
+	return z.decode([]byte(src))
+}
+
+func (z *Z85Encoder) encode(src []byte) []byte {
+	dst := make([]byte, 0, (len(src)+3)/4*5)
+	for len(src) > 0 {
+		var group [4]byte
+		n := copy(group[:], src)
+		src = src[n:]
+
+		v := binary.BigEndian.Uint32(group[:])
+		var digits [5]byte
+		for i := 4; i >= 0; i-- {
+			digits[i] = Z85Alphabet[v%85]
+			v /= 85
+		}
+		dst = append(dst, digits[:n+1]...)
+	}
+	return dst
+}
+
+func (z *Z85Encoder) decode(src []byte) ([]byte, error) {
+	dst := make([]byte, 0, len(src)/5*4+4)
+	for pos := 0; pos < len(src); pos += 5 {
+		chunk := src[pos:min(pos+5, len(src))]
+		if len(chunk) == 1 {
+			return nil, fmt.Errorf("illegal z85 data at input byte %d", pos)
+		}
+
+		var v uint64
+		for i := 0; i < 5; i++ {
+			digit := 84 // pad partial groups with the highest digit
+			if i < len(chunk) {
+				if digit = z.decodeMap[chunk[i]]; digit < 0 {
+					return nil, fmt.Errorf("illegal z85 data at input byte %d", pos+i)
+				}
+			}
+			v = v*85 + uint64(digit)
+		}
+		if v > 0xffffffff {
+			return nil, fmt.Errorf("illegal z85 data at input byte %d", pos)
+		}
+
+		var group [4]byte
+		binary.BigEndian.PutUint32(group[:], uint32(v))
+		dst = append(dst, group[:len(chunk)-1]...)
+	}
+	return dst, nil
+}
@@ -0,0 +1,40 @@
+package encode
+
+import (
+	"encoding/base32"
+	"io"
+	"strings"
+)
+
+// ZBase32Alphabet is the human oriented z-base-32 alphabet, it puts the
+// easiest to read and write characters where they occur most often.
+const ZBase32Alphabet = "ybndrfg8ejkmcpqxot1uwisza345h769"
+
+// ZBase32Encoder encodes lower case z-base-32 without padding, decoding ignores case
+type ZBase32Encoder struct {
+	enc *base32.Encoding
+}
+
+func NewZBase32Encoder() FormatCodec {
+	return &ZBase32Encoder{enc: base32.NewEncoding(ZBase32Alphabet).WithPadding(base32.NoPadding)}
+}
+
+func (z *ZBase32Encoder) Encode(dst []byte, src []byte) {
+	z.enc.Encode(dst, src)
+}
+
+func (z *ZBase32Encoder) EncodeToString(src []byte) string {
+	return z.enc.EncodeToString(src)
+}
+
+func (z *ZBase32Encoder) NewEncoder(w io.Writer) io.WriteCloser {
+	return base32.NewEncoder(z.enc, w)
+}
+
+func (z *ZBase32Encoder) Decode(dst []byte, src []byte) (int, error) {
+	return z.enc.Decode(dst, []byte(strings.ToLower(string(src))))
+}
+
+func (z *ZBase32Encoder) DecodeString(src string) ([]byte, error) {
+	return z.enc.DecodeString(strings.ToLower(src))
+}
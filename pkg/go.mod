@@ -3,8 +3,14 @@ module github.com/vldcreation/helpme-package/pkg
 go 1.24.1
 
 require (
+	github.com/cespare/xxhash/v2 v2.3.0
+	github.com/fxamacker/cbor/v2 v2.7.0
+	github.com/pelletier/go-toml/v2 v2.2.3
 	github.com/spf13/viper v1.20.0
+	github.com/subosito/gotenv v1.6.0
+	github.com/vmihailenco/msgpack/v5 v5.4.1
 	golang.design/x/clipboard v0.7.0
+	golang.org/x/crypto v0.33.0
 	golang.org/x/net v0.35.0
 	gopkg.in/yaml.v3 v3.0.1
 )
@@ -12,13 +18,13 @@ require (
 require (
 	github.com/fsnotify/fsnotify v1.8.0 // indirect
 	github.com/go-viper/mapstructure/v2 v2.2.1 // indirect
-	github.com/pelletier/go-toml/v2 v2.2.3 // indirect
 	github.com/sagikazarmark/locafero v0.7.0 // indirect
 	github.com/sourcegraph/conc v0.3.0 // indirect
 	github.com/spf13/afero v1.12.0 // indirect
 	github.com/spf13/cast v1.7.1 // indirect
 	github.com/spf13/pflag v1.0.6 // indirect
-	github.com/subosito/gotenv v1.6.0 // indirect
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
 	go.uber.org/atomic v1.9.0 // indirect
 	go.uber.org/multierr v1.9.0 // indirect
 	golang.org/x/exp v0.0.0-20190731235908-ec7cb31e5a56 // indirect
@@ -8,12 +8,14 @@ import (
 	"strings"
 	"time"
 
-	"golang.design/x/clipboard"
+	"github.com/vldcreation/helpme-package/pkg/clipboard"
 )
 
 type TrackClipboard struct {
 	Cfg     *Config
 	Channel TrackChannel
+	// Clipboard is watched by Track, clipboard.Detect picks one when nil
+	Clipboard clipboard.Clipboard
 }
 
 func NewTrackClipboard(cfg *Config) *TrackClipboard {
@@ -74,10 +76,16 @@ func NewTrackClipboard(cfg *Config) *TrackClipboard {
 	return t
 }
 
-func (t *TrackClipboard) Track() {
-	err := clipboard.Init()
-	if err != nil {
-		panic(err)
+// Track sends every change of the clipboard text to the channel until it
+// stays idle for Cfg.App.Idle. It fails when the clipboard cannot be
+// watched, e.g. OSC52 over SSH.
+func (t *TrackClipboard) Track() error {
+	if t.Clipboard == nil {
+		cb, err := clipboard.Detect()
+		if err != nil {
+			return err
+		}
+		t.Clipboard = cb
 	}
 
 	// Create a parent context for overall control
@@ -98,11 +106,18 @@ func (t *TrackClipboard) Track() {
 	defer timer.Stop()
 
 	// Watch clipboard changes
-	content := clipboard.Watch(ctx, clipboard.FmtText)
+	content, err := t.Clipboard.Watch(ctx, clipboard.FormatText)
+	if err != nil {
+		return err
+	}
 
 	for {
 		select {
-		case data := <-content:
+		case data, ok := <-content:
+			if !ok {
+				fmt.Println("Clipboard watch ended, stopping clipboard tracking")
+				return nil
+			}
 			// Reset timer when clipboard content changes
 			if !timer.Stop() {
 				select {
@@ -119,10 +134,10 @@ func (t *TrackClipboard) Track() {
 			}
 		case <-ctx.Done():
 			fmt.Println("Context canceled, stopping clipboard tracking")
-			return
+			return nil
 		case <-timer.C:
 			fmt.Println("Idle timeout, stopping clipboard tracking")
-			return
+			return nil
 		}
 	}
 }
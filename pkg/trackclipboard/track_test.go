@@ -0,0 +1,94 @@
+package trackclipboard
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vldcreation/helpme-package/pkg/clipboard"
+)
+
+type recordChannel struct {
+	mu     sync.Mutex
+	msgs   []string
+	closed bool
+}
+
+func (r *recordChannel) Send(ctx context.Context, msg string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.msgs = append(r.msgs, msg)
+	return nil
+}
+
+func (r *recordChannel) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+	return nil
+}
+
+// watchedMemory signals once Track watches the clipboard
+type watchedMemory struct {
+	*clipboard.Memory
+	watching chan struct{}
+}
+
+func (m *watchedMemory) Watch(ctx context.Context, format clipboard.Format) (<-chan []byte, error) {
+	ch, err := m.Memory.Watch(ctx, format)
+	close(m.watching)
+	return ch, err
+}
+
+func TestTrack(t *testing.T) {
+	cb := &watchedMemory{Memory: clipboard.NewMemory(), watching: make(chan struct{})}
+	channel := &recordChannel{}
+	tracker := &TrackClipboard{
+		Cfg:       &Config{App: &APPConfig{Channel: "record", Idle: 300 * time.Millisecond}},
+		Channel:   channel,
+		Clipboard: cb,
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- tracker.Track()
+	}()
+
+	<-cb.watching
+	for _, text := range []string{"first", "second"} {
+		cb.Write(context.Background(), clipboard.FormatText, []byte(text))
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Track() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Track() did not stop after the idle timeout")
+	}
+
+	channel.mu.Lock()
+	defer channel.mu.Unlock()
+	if want := []string{"first", "second"}; !slices.Equal(channel.msgs, want) {
+		t.Errorf("sent %q, want %q", channel.msgs, want)
+	}
+	if !channel.closed {
+		t.Errorf("channel not closed")
+	}
+}
+
+func TestTrackUnsupported(t *testing.T) {
+	tracker := &TrackClipboard{
+		Cfg:       &Config{App: &APPConfig{Channel: "record", Idle: time.Second}},
+		Channel:   &recordChannel{},
+		Clipboard: clipboard.NewOSC52(io.Discard),
+	}
+	if err := tracker.Track(); !errors.Is(err, clipboard.ErrUnsupported) {
+		t.Errorf("Track() error = %v, want %v", err, clipboard.ErrUnsupported)
+	}
+}
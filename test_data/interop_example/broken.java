@@ -0,0 +1,5 @@
+class broken {
+    public static void main(String[] args) {
+        System.out.print("missing semicolon")
+    }
+}
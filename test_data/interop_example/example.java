@@ -0,0 +1,5 @@
+class example {
+    public static void main(String[] args) {
+        System.out.print("Hello World from Java");
+    }
+}
@@ -0,0 +1 @@
+io.write("Hello World from Lua")
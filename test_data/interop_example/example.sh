@@ -0,0 +1 @@
+printf "Hello World from Bash"
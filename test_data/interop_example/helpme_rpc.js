@@ -0,0 +1,100 @@
+// JSON-RPC 2.0 over stdio shim for interop.RPCClient.
+//
+// Usage:
+//
+//   const rpc = require('./helpme_rpc');
+//   rpc.register('add', (a, b) => a + b);
+//   rpc.serve();
+//
+// Functions registered on the Go side are reachable through rpc.call.
+// stdout is reserved for the protocol, console.log is redirected to stderr.
+
+const readline = require('readline');
+
+const PARSE_ERROR = -32700;
+const METHOD_NOT_FOUND = -32601;
+const INTERNAL_ERROR = -32603;
+
+const handlers = {};
+const pending = new Map();
+let nextId = 1;
+
+console.log = console.error;
+
+class RPCError extends Error {
+  constructor(code, message, data) {
+    super(message);
+    this.code = code;
+    this.data = data;
+  }
+}
+
+function send(msg) {
+  process.stdout.write(JSON.stringify(msg) + '\n');
+}
+
+// register exposes fn to Go under name
+function register(name, fn) {
+  handlers[name] = fn;
+}
+
+// call invokes a handler registered with RPCClient.Handle and resolves with its result
+function call(method, ...params) {
+  const id = `js-${nextId++}`;
+  return new Promise((resolve, reject) => {
+    pending.set(id, { resolve, reject });
+    send({ jsonrpc: '2.0', id, method, params });
+  });
+}
+
+async function handle(msg) {
+  const resp = { jsonrpc: '2.0', id: msg.id };
+  const fn = handlers[msg.method];
+  const params = msg.params || [];
+  try {
+    if (!fn) {
+      throw new RPCError(METHOD_NOT_FOUND, `method "${msg.method}" not found`);
+    }
+    const result = Array.isArray(params) ? await fn(...params) : await fn(params);
+    resp.result = result === undefined ? null : result;
+  } catch (e) {
+    resp.error = {
+      code: e instanceof RPCError ? e.code : INTERNAL_ERROR,
+      message: e.message,
+      data: e instanceof RPCError ? e.data : e.stack,
+    };
+  }
+  if (msg.id !== undefined) {
+    send(resp);
+  }
+}
+
+// serve answers requests from Go until stdin is closed
+function serve() {
+  const rl = readline.createInterface({ input: process.stdin });
+  rl.on('line', (line) => {
+    let msg;
+    try {
+      msg = JSON.parse(line);
+    } catch (e) {
+      send({ jsonrpc: '2.0', id: null, error: { code: PARSE_ERROR, message: 'parse error' } });
+      return;
+    }
+    if (msg.method !== undefined) {
+      handle(msg);
+      return;
+    }
+    const p = pending.get(msg.id);
+    if (!p) {
+      return;
+    }
+    pending.delete(msg.id);
+    if (msg.error) {
+      p.reject(new RPCError(msg.error.code, msg.error.message, msg.error.data));
+    } else {
+      p.resolve(msg.result);
+    }
+  });
+}
+
+module.exports = { RPCError, register, call, serve };
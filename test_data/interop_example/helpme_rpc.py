@@ -0,0 +1,110 @@
+"""JSON-RPC 2.0 over stdio shim for interop.RPCClient.
+
+Usage:
+
+    import helpme_rpc
+
+    @helpme_rpc.register
+    def add(a, b):
+        return a + b
+
+    helpme_rpc.serve()
+
+Functions registered on the Go side are reachable through helpme_rpc.call.
+stdout is reserved for the protocol, print() is redirected to stderr.
+"""
+
+import itertools
+import json
+import sys
+import traceback
+
+PARSE_ERROR = -32700
+METHOD_NOT_FOUND = -32601
+INTERNAL_ERROR = -32603
+
+_out = sys.stdout
+sys.stdout = sys.stderr
+
+_handlers = {}
+_ids = itertools.count(1)
+
+
+class RPCError(Exception):
+    def __init__(self, code, message, data=None):
+        super().__init__(message)
+        self.code = code
+        self.message = message
+        self.data = data
+
+
+def register(fn=None, name=None):
+    """Expose fn to Go, usable as @register or @register(name="...")."""
+    if fn is None:
+        return lambda f: register(f, name)
+    _handlers[name or fn.__name__] = fn
+    return fn
+
+
+def call(method, *params):
+    """Call a handler registered with RPCClient.Handle and return its result."""
+    msg_id = "py-%d" % next(_ids)
+    _send({"jsonrpc": "2.0", "id": msg_id, "method": method, "params": list(params)})
+    while True:
+        msg = _read()
+        if msg is None:
+            raise RPCError(INTERNAL_ERROR, "connection closed")
+        if "method" in msg:
+            _handle(msg)
+            continue
+        if msg.get("id") != msg_id:
+            continue
+        if msg.get("error"):
+            err = msg["error"]
+            raise RPCError(err.get("code"), err.get("message"), err.get("data"))
+        return msg.get("result")
+
+
+def serve():
+    """Answer requests from Go until stdin is closed."""
+    while True:
+        msg = _read()
+        if msg is None:
+            return
+        if "method" in msg:
+            _handle(msg)
+
+
+def _send(msg):
+    _out.write(json.dumps(msg) + "\n")
+    _out.flush()
+
+
+def _read():
+    while True:
+        line = sys.stdin.readline()
+        if not line:
+            return None
+        try:
+            return json.loads(line)
+        except ValueError:
+            _send({"jsonrpc": "2.0", "id": None, "error": {"code": PARSE_ERROR, "message": "parse error"}})
+
+
+def _handle(msg):
+    resp = {"jsonrpc": "2.0", "id": msg.get("id")}
+    fn = _handlers.get(msg["method"])
+    params = msg.get("params") or []
+    try:
+        if fn is None:
+            raise RPCError(METHOD_NOT_FOUND, "method %r not found" % msg["method"])
+        if isinstance(params, dict):
+            resp["result"] = fn(**params)
+        else:
+            resp["result"] = fn(*params)
+    except RPCError as e:
+        resp["error"] = {"code": e.code, "message": e.message, "data": e.data}
+    except Exception as e:
+        resp["error"] = {"code": INTERNAL_ERROR, "message": str(e), "data": traceback.format_exc()}
+    if "id" in msg:
+        _send(resp)
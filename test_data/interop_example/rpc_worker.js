@@ -0,0 +1,20 @@
+const rpc = require('./helpme_rpc');
+
+rpc.register('add', (a, b) => a + b);
+
+rpc.register('upper', (s) => {
+  console.log('upper called'); // goes to stderr, stdout belongs to the protocol
+  return s.toUpperCase();
+});
+
+rpc.register('greet', async () => `Hello ${await rpc.call('name')} from Javascript`);
+
+rpc.register('fail', () => {
+  throw new Error('boom');
+});
+
+rpc.register('pid', () => process.pid);
+
+rpc.register('crash', () => process.exit(1));
+
+rpc.serve();
@@ -0,0 +1,43 @@
+import os
+import time
+
+import helpme_rpc
+
+
+@helpme_rpc.register
+def add(a, b):
+    return a + b
+
+
+@helpme_rpc.register
+def upper(s):
+    print("upper called")  # goes to stderr, stdout belongs to the protocol
+    return s.upper()
+
+
+@helpme_rpc.register
+def greet():
+    return "Hello %s from Python" % helpme_rpc.call("name")
+
+
+@helpme_rpc.register
+def fail():
+    raise ValueError("boom")
+
+
+@helpme_rpc.register
+def pid():
+    return os.getpid()
+
+
+@helpme_rpc.register
+def sleep(seconds):
+    time.sleep(seconds)
+
+
+@helpme_rpc.register
+def crash():
+    os._exit(1)
+
+
+helpme_rpc.serve()
@@ -0,0 +1,4 @@
+import os
+
+# crashes the way a failed allocation may, without using any memory
+os.abort()
@@ -0,0 +1,5 @@
+import sys
+
+# uses most of the memory limit legitimately and fails for another reason
+data = bytearray(150 * 1024 * 1024)
+sys.exit(3)
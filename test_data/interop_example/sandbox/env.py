@@ -0,0 +1,3 @@
+import os
+
+print(",".join(sorted(os.environ)), end="")
@@ -0,0 +1,4 @@
+import sys
+
+# fails like an allocation would, without using any memory
+sys.exit("MemoryError: out of memory")
@@ -0,0 +1,6 @@
+import resource
+
+# the limits must already be in place when the script starts
+cpu = resource.getrlimit(resource.RLIMIT_CPU)[0]
+data = resource.getrlimit(resource.RLIMIT_DATA)[0]
+print(f"{cpu} {data}", end="")
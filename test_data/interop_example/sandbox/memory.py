@@ -0,0 +1,4 @@
+chunks = []
+while True:
+    # small chunks run the process up to the limit before an allocation fails
+    chunks.append(bytearray(8 * 1024 * 1024))
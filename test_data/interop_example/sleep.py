@@ -0,0 +1,13 @@
+import os
+import subprocess
+import sys
+
+# spawn a grandchild so cancellation has to take down the whole process group
+child = subprocess.Popen(["sleep", "30"])
+
+# report the grandchild and the process group to the test, if it asks
+if len(sys.argv) > 1:
+    with open(sys.argv[1], "w") as f:
+        f.write(f"{child.pid} {os.getpgid(0)}")
+
+child.wait()